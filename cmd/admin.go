@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"time"
+
+	"review-service/internal/audit"
+	"review-service/internal/model"
+	"review-service/internal/oidc"
+	"review-service/internal/service"
+	"review-service/internal/teamfile"
+	"review-service/pkg/config"
+)
+
+const adminUsage = `usage:
+  review-service import [--dry-run] [--format yaml|csv] FILE
+  review-service export [--format yaml|csv]
+  review-service token issue --user USER_ID --name NAME --scopes read,write,admin
+  review-service token revoke TOKEN_ID
+  review-service oidc-stub keygen [--key FILE] [--jwks FILE]
+  review-service oidc-stub token --user USER_ID [--scopes read,write] [--groups GROUP,...] [--key FILE]`
+
+// adminFlags флаги подкоманд командной строки
+type adminFlags struct {
+	Format string
+	DryRun bool
+	User   string
+	Name   string
+	Scopes []string
+	Groups []string
+	Key    string
+	JWKS   string
+}
+
+// discardNotifier глушит уведомления в административных командах: импорт никого не назначает
+type discardNotifier struct{}
+
+func (discardNotifier) Notify(*model.NotificationEvent) {}
+
+// cliActor — действующее лицо для журнала аудита: пользователь ОС, запустивший команду
+func cliActor() audit.Actor {
+	actor := audit.Actor{Type: audit.ActorCLI, ID: "cli"}
+	if current, err := user.Current(); err == nil {
+		actor.ID = current.Username
+	}
+	return actor
+}
+
+// runAdminCommand выполняет подкоманду командной строки и возвращает код выхода
+func runAdminCommand(ctx context.Context, svc service.Service, args []string, flags adminFlags) int {
+	ctx = audit.WithActor(ctx, cliActor())
+	format := flags.Format
+	switch args[0] {
+	case "import":
+		if len(args) != 2 {
+			fmt.Fprintln(os.Stderr, adminUsage)
+			return 2
+		}
+		return runImport(ctx, svc, args[1], format, flags.DryRun)
+	case "token":
+		return runToken(ctx, svc, args[1:], flags)
+	case "export":
+		if format == "" {
+			format = teamfile.FormatYAML
+		}
+		teams, err := svc.ExportTeams(ctx)
+		if err == nil {
+			err = teamfile.Write(os.Stdout, format, teams)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "export failed: %v\n", err)
+			return 1
+		}
+		return 0
+	default:
+		fmt.Fprintln(os.Stderr, adminUsage)
+		return 2
+	}
+}
+
+func runImport(ctx context.Context, svc service.Service, path, format string, dryRun bool) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import failed: %v\n", err)
+		return 1
+	}
+	if format == "" {
+		format = teamfile.FormatFromName(path)
+	}
+
+	result, err := svc.ImportTeams(ctx, format, data, dryRun)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import failed: %v\n", err)
+		return 1
+	}
+
+	for _, importErr := range result.Errors {
+		fmt.Fprintf(os.Stderr, "%s:%d: %s\n", path, importErr.Line, importErr.Message)
+	}
+	for _, change := range result.Changes {
+		line, _ := json.Marshal(change)
+		fmt.Println(string(line))
+	}
+	if len(result.Errors) > 0 {
+		return 1
+	}
+
+	switch {
+	case result.Applied:
+		fmt.Fprintf(os.Stderr, "applied %d changes\n", len(result.Changes))
+	case dryRun:
+		fmt.Fprintf(os.Stderr, "dry run: %d changes, nothing applied\n", len(result.Changes))
+	default:
+		fmt.Fprintln(os.Stderr, "nothing to change")
+	}
+	return 0
+}
+
+// runToken выпускает и отзывает API-токены; так выдаётся первый admin-токен
+func runToken(ctx context.Context, svc service.Service, args []string, flags adminFlags) int {
+	switch {
+	case len(args) == 1 && args[0] == "issue":
+		issued, err := svc.IssueAPIToken(ctx, flags.User, flags.Name, flags.Scopes, nil)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "token issue failed: %v\n", err)
+			return 1
+		}
+		fmt.Println(issued.Token)
+		fmt.Fprintf(os.Stderr, "issued token %d for %s\n", issued.Info.TokenID, issued.Info.UserID)
+		return 0
+	case len(args) == 2 && args[0] == "revoke":
+		tokenID, err := strconv.ParseInt(args[1], 10, 64)
+		if err == nil {
+			err = svc.RevokeAPIToken(ctx, tokenID, "")
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "token revoke failed: %v\n", err)
+			return 1
+		}
+		return 0
+	default:
+		fmt.Fprintln(os.Stderr, adminUsage)
+		return 2
+	}
+}
+
+// runOIDCStub — локальный издатель JWT для разработки: keygen создаёт ключ и JWKS
+// (подключается через OIDC_JWKS_FILE), token выпускает токен на час с iss, aud и группами в claim из конфигурации
+func runOIDCStub(args []string, cfg config.OIDCConfig, flags adminFlags) int {
+	switch {
+	case len(args) == 1 && args[0] == "keygen":
+		if err := oidc.GenerateStubKey(flags.Key, flags.JWKS); err != nil {
+			fmt.Fprintf(os.Stderr, "keygen failed: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(os.Stderr, "wrote %s and %s\n", flags.Key, flags.JWKS)
+		return 0
+	case len(args) == 1 && args[0] == "token":
+		token, err := oidc.IssueStubToken(flags.Key, cfg, flags.User, flags.Scopes, flags.Groups, time.Hour)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "token failed: %v\n", err)
+			return 1
+		}
+		fmt.Println(token)
+		return 0
+	default:
+		fmt.Fprintln(os.Stderr, adminUsage)
+		return 2
+	}
+}
@@ -2,38 +2,117 @@ package main
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"os"
+	"time"
 
+	"review-service/internal/codehost"
 	"review-service/internal/handler"
+	"review-service/internal/integration/github"
+	"review-service/internal/logging"
+	"review-service/internal/metrics"
+	"review-service/internal/model"
+	"review-service/internal/notification"
+	"review-service/internal/oidc"
 	"review-service/internal/repository"
 	"review-service/internal/service"
+	"review-service/internal/tracing"
 	"review-service/pkg/config"
 	"review-service/pkg/database"
 	"review-service/pkg/server"
+
+	"github.com/spf13/pflag"
 )
 
 func main() {
-	log.SetOutput(os.Stdout)
+	var flags adminFlags
+	pflag.BoolVar(&flags.DryRun, "dry-run", false, "import: show changes without applying them")
+	pflag.StringVar(&flags.Format, "format", "", "import/export file format: yaml or csv")
+	pflag.StringVar(&flags.User, "user", "", "token issue: owner user_id")
+	pflag.StringVar(&flags.Name, "name", "", "token issue: token name")
+	pflag.StringSliceVar(&flags.Scopes, "scopes", nil, "token issue: scopes (read, write, admin)")
+	pflag.StringSliceVar(&flags.Groups, "groups", nil, "oidc-stub token: groups claim values")
+	pflag.StringVar(&flags.Key, "key", "oidc-stub.pem", "oidc-stub: private key file")
+	pflag.StringVar(&flags.JWKS, "jwks", "oidc-stub.jwks.json", "oidc-stub keygen: JWKS output file")
 
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
+		fatal("failed to load config", err)
 	}
+	logging.Setup(cfg.LogLevel)
 
 	ctx := context.Background()
 
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		fatal("failed to set up tracing", err)
+	}
+	flushTraces := func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			slog.Error("failed to flush traces", "error", err)
+		}
+	}
+	defer flushTraces()
+
+	// Stub-издатель OIDC не нуждается в базе
+	if args := pflag.Args(); len(args) > 0 && args[0] == "oidc-stub" {
+		os.Exit(runOIDCStub(args[1:], cfg.OIDC, flags))
+	}
+
 	dbPool, err := database.New(ctx, cfg.DB)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		fatal("failed to connect to database", err)
 	}
 	defer dbPool.Close()
 
 	repo := repository.NewPostgresRepository(dbPool)
+	metrics.RegisterPool(dbPool)
+	metrics.RegisterOpenReviews(repo)
+
+	if args := pflag.Args(); len(args) > 0 {
+		code := runAdminCommand(ctx, service.NewService(repo, discardNotifier{}), args, flags)
+		dbPool.Close()
+		flushTraces()
+		os.Exit(code)
+	}
 
-	svc := service.NewService(repo)
+	notifyCtx, stopNotify := context.WithCancel(ctx)
+	defer stopNotify()
+	channels := map[string]notification.Channel{
+		model.NotificationChannelSlack:   notification.NewSlackChannel(),
+		model.NotificationChannelWebhook: notification.NewWebhookChannel(),
+	}
+	if cfg.SMTP.Host != "" {
+		mailer := notification.NewMailer(cfg.SMTP)
+		channels[model.NotificationChannelEmail] = notification.NewEmailChannel(mailer)
+		go notification.NewDigestScheduler(repo, mailer, cfg.SMTP.DigestHour).Run(notifyCtx)
+	}
+	dispatcher := notification.NewDispatcher(repo, channels, 1000)
+	dispatcher.Start(notifyCtx, 4)
 
-	router := handler.NewHandler(svc)
+	svc := service.NewService(repo, dispatcher)
+
+	codeHostClients := map[string]codehost.CodeHostClient{}
+	if cfg.Integrations.GitHubAPIToken != "" {
+		codeHostClients[github.Provider] = codehost.NewGitHubClient(cfg.Integrations.GitHubAPIURL, cfg.Integrations.GitHubAPIToken)
+	}
+	syncCtx, stopSync := context.WithCancel(ctx)
+	defer stopSync()
+	go codehost.NewSyncer(repo, codeHostClients, 10*time.Second).Run(syncCtx)
+
+	verifier, err := oidc.NewVerifier(ctx, cfg.OIDC)
+	if err != nil {
+		fatal("failed to set up OIDC", err)
+	}
+
+	router := handler.NewHandler(svc, cfg.Integrations, verifier)
 
 	server.Start(cfg.Port, router)
-}
\ No newline at end of file
+}
+
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
@@ -0,0 +1,36 @@
+// Package audit передаёт через контекст того, от чьего имени выполняется изменение,
+// чтобы репозиторий записал его в audit_log в той же транзакции.
+package audit
+
+import "context"
+
+// Типы действующих лиц. Для пользователей это способ аутентификации, для прочих — источник вызова.
+const (
+	ActorAPIToken = "api_token"
+	ActorOIDC     = "oidc"
+	ActorWebhook  = "webhook"
+	ActorSCIM     = "scim"
+	ActorCLI      = "cli"
+	ActorSystem   = "system"
+)
+
+// Actor — кто выполняет изменение: пользователь (ID — user_id) или источник вроде вебхука (ID — провайдер)
+type Actor struct {
+	Type string
+	ID   string
+}
+
+type actorKey struct{}
+
+// WithActor сохраняет действующее лицо в контексте
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext возвращает действующее лицо; без него изменение считается системным
+func ActorFromContext(ctx context.Context) Actor {
+	if actor, ok := ctx.Value(actorKey{}).(Actor); ok {
+		return actor
+	}
+	return Actor{Type: ActorSystem, ID: "system"}
+}
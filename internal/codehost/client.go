@@ -0,0 +1,9 @@
+package codehost
+
+import "context"
+
+// CodeHostClient интерфейс для обратной синхронизации ревьюеров с code host'ом
+type CodeHostClient interface {
+	RequestReviewers(ctx context.Context, repository string, number int, logins []string) error
+	RemoveReviewers(ctx context.Context, repository string, number int, logins []string) error
+}
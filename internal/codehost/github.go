@@ -0,0 +1,102 @@
+package codehost
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const defaultGitHubAPIURL = "https://api.github.com"
+
+type githubClient struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+	retries    int
+	backoff    time.Duration
+}
+
+// NewGitHubClient создаёт клиент GitHub REST API. Пустой baseURL означает api.github.com.
+func NewGitHubClient(baseURL, token string) CodeHostClient {
+	if baseURL == "" {
+		baseURL = defaultGitHubAPIURL
+	}
+	return &githubClient{
+		baseURL:    baseURL,
+		token:      token,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		retries:    3,
+		backoff:    500 * time.Millisecond,
+	}
+}
+
+func (c *githubClient) RequestReviewers(ctx context.Context, repository string, number int, logins []string) error {
+	return c.reviewersRequest(ctx, http.MethodPost, repository, number, logins)
+}
+
+func (c *githubClient) RemoveReviewers(ctx context.Context, repository string, number int, logins []string) error {
+	return c.reviewersRequest(ctx, http.MethodDelete, repository, number, logins)
+}
+
+func (c *githubClient) reviewersRequest(ctx context.Context, method, repository string, number int, logins []string) error {
+	body, err := json.Marshal(map[string][]string{"reviewers": logins})
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/repos/%s/pulls/%d/requested_reviewers", c.baseURL, repository, number)
+
+	var lastErr error
+	for attempt := 0; attempt < c.retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(c.backoff << (attempt - 1)):
+			}
+		}
+
+		retry, err := c.do(ctx, method, url, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+
+	return lastErr
+}
+
+// do выполняет один запрос и сообщает, имеет ли смысл его повторить
+func (c *githubClient) do(ctx context.Context, method, url string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	err = fmt.Errorf("github %s %s: status %d: %s", method, url, resp.StatusCode, bytes.TrimSpace(msg))
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, err
+}
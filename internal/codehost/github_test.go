@@ -0,0 +1,139 @@
+package codehost
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestClient направляет клиент на фейковый сервер и укорачивает задержку между попытками
+func newTestClient(t *testing.T, handler http.HandlerFunc) *githubClient {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := NewGitHubClient(server.URL, "gh-token").(*githubClient)
+	client.backoff = time.Millisecond
+	return client
+}
+
+func TestGitHubReviewersRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		call   func(c *githubClient) error
+	}{
+		{"request", http.MethodPost, func(c *githubClient) error {
+			return c.RequestReviewers(context.Background(), "acme/payments", 42, []string{"octo-alice", "octo-bob"})
+		}},
+		{"remove", http.MethodDelete, func(c *githubClient) error {
+			return c.RemoveReviewers(context.Background(), "acme/payments", 42, []string{"octo-alice", "octo-bob"})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				if r.Method != tt.method || r.URL.Path != "/repos/acme/payments/pulls/42/requested_reviewers" {
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+				if got := r.Header.Get("Authorization"); got != "Bearer gh-token" {
+					t.Errorf("Authorization = %q", got)
+				}
+				if got := r.Header.Get("Accept"); got != "application/vnd.github+json" {
+					t.Errorf("Accept = %q", got)
+				}
+
+				var body struct {
+					Reviewers []string `json:"reviewers"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("decode body: %v", err)
+				}
+				if !slices.Equal(body.Reviewers, []string{"octo-alice", "octo-bob"}) {
+					t.Errorf("reviewers = %v", body.Reviewers)
+				}
+				w.WriteHeader(http.StatusCreated)
+			})
+
+			if err := tt.call(client); err != nil {
+				t.Fatal(err)
+			}
+			if calls.Load() != 1 {
+				t.Fatalf("expected one call, got %d", calls.Load())
+			}
+		})
+	}
+}
+
+func TestGitHubRetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	if err := client.RequestReviewers(context.Background(), "acme/payments", 42, []string{"octo-alice"}); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("expected 3 attempts, got %d", calls.Load())
+	}
+}
+
+func TestGitHubGivesUpAfterRetries(t *testing.T) {
+	var calls atomic.Int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	if err := client.RequestReviewers(context.Background(), "acme/payments", 42, []string{"octo-alice"}); err == nil {
+		t.Fatal("expected error after exhausting retries")
+	}
+	if int(calls.Load()) != client.retries {
+		t.Fatalf("expected %d attempts, got %d", client.retries, calls.Load())
+	}
+}
+
+func TestGitHubDoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	})
+
+	if err := client.RemoveReviewers(context.Background(), "acme/payments", 42, []string{"octo-alice"}); err == nil {
+		t.Fatal("expected error")
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected a single attempt, got %d", calls.Load())
+	}
+}
+
+func TestGitHubBackoffGrows(t *testing.T) {
+	var stamps []time.Time
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		stamps = append(stamps, time.Now())
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	client.backoff = 20 * time.Millisecond
+
+	_ = client.RequestReviewers(context.Background(), "acme/payments", 42, []string{"octo-alice"})
+	if len(stamps) != 3 {
+		t.Fatalf("expected 3 attempts, got %d", len(stamps))
+	}
+	if first, second := stamps[1].Sub(stamps[0]), stamps[2].Sub(stamps[1]); first < 20*time.Millisecond || second < 40*time.Millisecond {
+		t.Fatalf("backoff too short: %v, %v", first, second)
+	}
+}
@@ -0,0 +1,114 @@
+package codehost
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"review-service/internal/model"
+	"review-service/internal/repository"
+	"time"
+)
+
+const (
+	syncBatchSize   = 20
+	syncMaxAttempts = 10
+	syncLease       = 2 * time.Minute
+	syncBaseBackoff = 30 * time.Second
+	syncMaxBackoff  = time.Hour
+)
+
+// Syncer разбирает очередь reviewer_sync_jobs и отправляет изменения ревьюеров в code host.
+// Неудачные задания откладываются с экспоненциальной задержкой.
+type Syncer struct {
+	repo     repository.Repository
+	clients  map[string]CodeHostClient
+	interval time.Duration
+}
+
+func NewSyncer(repo repository.Repository, clients map[string]CodeHostClient, interval time.Duration) *Syncer {
+	return &Syncer{repo: repo, clients: clients, interval: interval}
+}
+
+func (s *Syncer) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.ProcessPending(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "reviewer sync failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Syncer) ProcessPending(ctx context.Context) error {
+	jobs, err := s.repo.ClaimReviewerSyncJobs(ctx, syncBatchSize, syncMaxAttempts, syncLease)
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		if err := s.process(ctx, job); err != nil {
+			retryAt := time.Now().Add(syncBackoff(job.Attempts))
+			slog.WarnContext(ctx, "reviewer sync job failed", "job_id", job.ID, "pull_request_id", job.PullRequestID, "attempt", job.Attempts, "retry_at", retryAt, "error", err)
+			if err := s.repo.FailReviewerSyncJob(ctx, job.ID, err.Error(), retryAt); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := s.repo.CompleteReviewerSyncJob(ctx, job.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Syncer) process(ctx context.Context, job *model.ReviewerSyncJob) error {
+	link, err := s.repo.GetPullRequestLink(ctx, job.PullRequestID)
+	if err != nil {
+		return err
+	}
+
+	// Для провайдера без клиента (GitLab, GitHub без токена) отправлять некуда: задание закрывается
+	// сразу, а не повторяется до исчерпания попыток
+	client, ok := s.clients[link.Provider]
+	if !ok {
+		slog.DebugContext(ctx, "no code host client, skipping reviewer sync", "job_id", job.ID, "provider", link.Provider)
+		return nil
+	}
+
+	logins, err := s.repo.GetIdentityLogins(ctx, link.Provider, job.UserIDs)
+	if err != nil {
+		return err
+	}
+	if len(logins) == 0 {
+		return nil
+	}
+
+	switch job.Action {
+	case model.ReviewerSyncRequest:
+		return client.RequestReviewers(ctx, link.Repository, link.Number, logins)
+	case model.ReviewerSyncRemove:
+		return client.RemoveReviewers(ctx, link.Repository, link.Number, logins)
+	default:
+		return fmt.Errorf("unknown reviewer sync action %q", job.Action)
+	}
+}
+
+func syncBackoff(attempts int) time.Duration {
+	backoff := syncBaseBackoff
+	for i := 1; i < attempts && backoff < syncMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > syncMaxBackoff {
+		backoff = syncMaxBackoff
+	}
+	return backoff
+}
@@ -0,0 +1,79 @@
+package codehost
+
+import (
+	"context"
+	"review-service/internal/model"
+	"review-service/internal/repository"
+	"testing"
+	"time"
+)
+
+type syncRepo struct {
+	repository.Repository
+	jobs      []*model.ReviewerSyncJob
+	links     map[string]*model.PullRequestLink
+	completed []int64
+	failed    []int64
+}
+
+func (r *syncRepo) ClaimReviewerSyncJobs(ctx context.Context, limit, maxAttempts int, lease time.Duration) ([]*model.ReviewerSyncJob, error) {
+	jobs := r.jobs
+	r.jobs = nil
+	return jobs, nil
+}
+
+func (r *syncRepo) GetPullRequestLink(ctx context.Context, prID string) (*model.PullRequestLink, error) {
+	return r.links[prID], nil
+}
+
+func (r *syncRepo) GetIdentityLogins(ctx context.Context, provider string, userIDs []string) ([]string, error) {
+	return userIDs, nil
+}
+
+func (r *syncRepo) CompleteReviewerSyncJob(ctx context.Context, jobID int64) error {
+	r.completed = append(r.completed, jobID)
+	return nil
+}
+
+func (r *syncRepo) FailReviewerSyncJob(ctx context.Context, jobID int64, cause string, retryAt time.Time) error {
+	r.failed = append(r.failed, jobID)
+	return nil
+}
+
+type recordingClient struct {
+	requested [][]string
+}
+
+func (c *recordingClient) RequestReviewers(ctx context.Context, repository string, number int, logins []string) error {
+	c.requested = append(c.requested, logins)
+	return nil
+}
+
+func (c *recordingClient) RemoveReviewers(ctx context.Context, repository string, number int, logins []string) error {
+	return nil
+}
+
+func TestSyncerSkipsProvidersWithoutClient(t *testing.T) {
+	repo := &syncRepo{
+		jobs: []*model.ReviewerSyncJob{
+			{ID: 1, PullRequestID: "github:acme/payments#42", Action: model.ReviewerSyncRequest, UserIDs: []string{"octo-alice"}},
+			{ID: 2, PullRequestID: "gitlab:platform/billing!17", Action: model.ReviewerSyncRequest, UserIDs: []string{"gl-carol"}},
+		},
+		links: map[string]*model.PullRequestLink{
+			"github:acme/payments#42":    {Provider: "github", Repository: "acme/payments", Number: 42},
+			"gitlab:platform/billing!17": {Provider: "gitlab", Repository: "platform/billing", Number: 17},
+		},
+	}
+	client := &recordingClient{}
+	syncer := NewSyncer(repo, map[string]CodeHostClient{"github": client}, time.Second)
+
+	if err := syncer.ProcessPending(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.completed) != 2 || len(repo.failed) != 0 {
+		t.Fatalf("completed %v, failed %v", repo.completed, repo.failed)
+	}
+	if len(client.requested) != 1 {
+		t.Fatalf("expected only the GitHub job to reach the client, got %v", client.requested)
+	}
+}
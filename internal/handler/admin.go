@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"review-service/internal/model"
+	"review-service/internal/teamfile"
+	"strconv"
+)
+
+const maxImportBodySize = 10 << 20
+
+func (h *Handler) importTeams(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = formatFromContentType(r.Header.Get("Content-Type"))
+	}
+	if format != teamfile.FormatYAML && format != teamfile.FormatCSV {
+		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "format must be yaml or csv"))
+		return
+	}
+
+	dryRun := false
+	if value := r.URL.Query().Get("dry_run"); value != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Invalid dry_run parameter"))
+			return
+		}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxImportBodySize))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	result, err := h.service.ImportTeams(r.Context(), format, body, dryRun)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	status := http.StatusOK
+	if len(result.Errors) > 0 {
+		status = http.StatusBadRequest
+	}
+	writeJSON(w, status, result)
+}
+
+func (h *Handler) exportTeams(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = teamfile.FormatYAML
+	}
+	if format != teamfile.FormatYAML && format != teamfile.FormatCSV {
+		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "format must be yaml or csv"))
+		return
+	}
+
+	teams, err := h.service.ExportTeams(r.Context())
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := teamfile.Write(&buf, format, teams); err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	contentType := "application/yaml"
+	if format == teamfile.FormatCSV {
+		contentType = "text/csv"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", "attachment; filename=teams."+format)
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+func formatFromContentType(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv":
+		return teamfile.FormatCSV
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return teamfile.FormatYAML
+	}
+	return ""
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"net/http"
+	"review-service/internal/audit"
+	"review-service/internal/model"
+)
+
+// auditAs помечает изменения, сделанные запросом, как выполненные источником без пользователя
+// (вебхук, SCIM); для аутентифицированных запросов действующее лицо задаёт authenticate
+func auditAs(actorType, actorID string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := audit.WithActor(r.Context(), audit.Actor{Type: actorType, ID: actorID})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// listAuditLog отдаёт журнал изменений, новые записи первыми.
+// Фильтры: actor, action, target_type, target_id, request_id и период from/to как у статистики.
+func (h *Handler) listAuditLog(w http.ResponseWriter, r *http.Request) {
+	period, ok := statsFilter(w, r)
+	if !ok {
+		return
+	}
+	limit, offset, ok := pageParams(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	log, err := h.service.ListAuditLog(r.Context(), model.AuditFilter{
+		ActorID:    query.Get("actor"),
+		Action:     query.Get("action"),
+		TargetType: query.Get("target_type"),
+		TargetID:   query.Get("target_id"),
+		RequestID:  query.Get("request_id"),
+		From:       period.From,
+		To:         period.To,
+		Limit:      limit,
+		Offset:     offset,
+	})
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, log)
+}
@@ -0,0 +1,231 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"review-service/internal/audit"
+	"review-service/internal/model"
+	"review-service/internal/service"
+	"strings"
+	"time"
+)
+
+type principalKey struct{}
+
+// principal возвращает вызывающего, аутентифицированного authenticate
+func principal(r *http.Request) *model.Principal {
+	p, _ := r.Context().Value(principalKey{}).(*model.Principal)
+	return p
+}
+
+// authenticate требует заголовок Authorization: Bearer с API-токеном сервиса (rvs_...)
+// или, если настроен OIDC, с JWT от SSO, у которого sub — user_id активного пользователя
+func (h *Handler) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || raw == "" {
+			writeUnauthorized(w, "Missing bearer token")
+			return
+		}
+
+		var p *model.Principal
+		var err error
+		if h.oidc != nil && !service.IsAPIToken(raw) {
+			p, err = h.authenticateJWT(r, raw)
+		} else {
+			p, err = h.authenticateAPIToken(r, raw)
+		}
+		if err != nil {
+			if businessErr, ok := err.(service.BusinessError); ok && businessErr.Code == "UNAUTHORIZED" {
+				writeUnauthorized(w, businessErr.Message)
+				return
+			}
+			handleServiceError(w, r, err)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), principalKey{}, p)
+		ctx = audit.WithActor(ctx, audit.Actor{Type: p.Method, ID: p.UserID})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func (h *Handler) authenticateAPIToken(r *http.Request, raw string) (*model.Principal, error) {
+	token, err := h.service.AuthenticateAPIToken(r.Context(), raw)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Principal{UserID: token.UserID, Scopes: token.Scopes, Method: model.AuthMethodAPIToken}, nil
+}
+
+func (h *Handler) authenticateJWT(r *http.Request, raw string) (*model.Principal, error) {
+	claims, err := h.oidc.Verify(r.Context(), raw)
+	if err != nil {
+		slog.InfoContext(r.Context(), "jwt rejected", "error", err)
+		return nil, service.NewBusinessError("UNAUTHORIZED", "invalid token", err)
+	}
+
+	user, err := h.service.GetUser(r.Context(), claims.Subject)
+	if err != nil {
+		if businessErr, ok := err.(service.BusinessError); ok && businessErr.Code == "NOT_FOUND" {
+			return nil, service.NewBusinessError("UNAUTHORIZED", "unknown user", err)
+		}
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, service.NewBusinessError("UNAUTHORIZED", "user is inactive", nil)
+	}
+
+	return &model.Principal{UserID: user.UserID, Scopes: claims.Scopes, Method: model.AuthMethodOIDC}, nil
+}
+
+// requireScope пропускает только токены с областью доступа не ниже scope
+func requireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if p := principal(r); p == nil || !p.HasScope(scope) {
+				writeError(w, http.StatusForbidden, model.NewErrorResponse("FORBIDDEN", "Token lacks "+scope+" scope"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// authorizeUser разрешает действие от имени userID самому пользователю и администраторам;
+// при отказе сам отвечает 403
+func authorizeUser(w http.ResponseWriter, r *http.Request, userID string) bool {
+	p := principal(r)
+	if p.HasScope(model.ScopeAdmin) || p.UserID == userID {
+		return true
+	}
+	writeError(w, http.StatusForbidden, model.NewErrorResponse("FORBIDDEN", "Only the user or an admin can do this"))
+	return false
+}
+
+// authorizePullRequestAuthor разрешает действие с PR только его автору и администраторам
+func (h *Handler) authorizePullRequestAuthor(w http.ResponseWriter, r *http.Request, prID string) bool {
+	if principal(r).HasScope(model.ScopeAdmin) {
+		return true
+	}
+
+	pr, err := h.service.GetPullRequest(r.Context(), prID)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return false
+	}
+	if pr.AuthorID != principal(r).UserID {
+		writeError(w, http.StatusForbidden, model.NewErrorResponse("FORBIDDEN", "Only the PR author or an admin can do this"))
+		return false
+	}
+	return true
+}
+
+// authorizePullRequestReviewer разрешает действие с ревьюером userID в PR автору PR, самому ревьюеру
+// и администраторам
+func (h *Handler) authorizePullRequestReviewer(w http.ResponseWriter, r *http.Request, prID, userID string) bool {
+	p := principal(r)
+	if p.HasScope(model.ScopeAdmin) || (userID != "" && p.UserID == userID) {
+		return true
+	}
+
+	pr, err := h.service.GetPullRequest(r.Context(), prID)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return false
+	}
+	if pr.AuthorID != p.UserID {
+		writeError(w, http.StatusForbidden, model.NewErrorResponse("FORBIDDEN", "Only the PR author, the reviewer or an admin can do this"))
+		return false
+	}
+	return true
+}
+
+func writeUnauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="review-service"`)
+	writeError(w, http.StatusUnauthorized, model.NewErrorResponse("UNAUTHORIZED", message))
+}
+
+// issueToken выпускает токен. Без admin можно выпустить токен только себе и не шире собственного.
+func (h *Handler) issueToken(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		UserID    string     `json:"user_id"`
+		Name      string     `json:"name"`
+		Scopes    []string   `json:"scopes"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	caller := principal(r)
+	if req.UserID == "" {
+		req.UserID = caller.UserID
+	}
+	if !authorizeUser(w, r, req.UserID) {
+		return
+	}
+	for _, scope := range req.Scopes {
+		if model.ValidScope(scope) && !caller.HasScope(scope) {
+			writeError(w, http.StatusForbidden, model.NewErrorResponse("FORBIDDEN", "Cannot issue a token with "+scope+" scope"))
+			return
+		}
+	}
+
+	issued, err := h.service.IssueAPIToken(r.Context(), req.UserID, req.Name, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, issued)
+}
+
+func (h *Handler) revokeToken(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TokenID int64 `json:"token_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	// Не-администратор отзывает только свои токены одним запросом с фильтром по владельцу:
+	// чужой токен даёт тот же 404, что и несуществующий, и не выдаёт, какие id заняты
+	ownerID := ""
+	if caller := principal(r); !caller.HasScope(model.ScopeAdmin) {
+		ownerID = caller.UserID
+	}
+
+	if err := h.service.RevokeAPIToken(r.Context(), req.TokenID, ownerID); err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// listTokens показывает токены пользователя (по умолчанию — свои); admin без user_id видит все
+func (h *Handler) listTokens(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	caller := principal(r)
+	if userID == "" && !caller.HasScope(model.ScopeAdmin) {
+		userID = caller.UserID
+	}
+	if userID != "" && !authorizeUser(w, r, userID) {
+		return
+	}
+
+	tokens, err := h.service.ListAPITokens(r.Context(), userID)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"tokens": tokens})
+}
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"review-service/internal/model"
+	"review-service/internal/service"
+	"review-service/pkg/config"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeService принимает токены вида "<user_id>:<scope>" и знает один PR
+type fakeService struct {
+	service.Service
+	pr    *model.PullRequest
+	users map[string]*model.User
+	calls []string
+}
+
+func newFakeService() *fakeService {
+	return &fakeService{
+		pr: &model.PullRequest{
+			PullRequestID: "pr-1", AuthorID: "author", Status: "OPEN",
+			AssignedReviewers: []string{"reviewer"}, ShadowReviewers: []string{"trainee"},
+		},
+		users: map[string]*model.User{},
+	}
+}
+
+func (s *fakeService) AuthenticateAPIToken(ctx context.Context, token string) (*model.APIToken, error) {
+	userID, scope, ok := strings.Cut(token, ":")
+	if !ok {
+		return nil, service.NewBusinessError("UNAUTHORIZED", "invalid token", nil)
+	}
+	return &model.APIToken{UserID: userID, Scopes: []string{scope}}, nil
+}
+
+func (s *fakeService) GetUser(ctx context.Context, userID string) (*model.User, error) {
+	user, ok := s.users[userID]
+	if !ok {
+		return nil, service.NewBusinessError("NOT_FOUND", "user not found", nil)
+	}
+	return user, nil
+}
+
+func (s *fakeService) GetPullRequest(ctx context.Context, prID string) (*model.PullRequest, error) {
+	if prID != s.pr.PullRequestID {
+		return nil, service.NewBusinessError("NOT_FOUND", "PR not found", nil)
+	}
+	return s.pr, nil
+}
+
+func (s *fakeService) AddShadowReviewer(ctx context.Context, prID, userID string) (*model.PullRequest, error) {
+	s.calls = append(s.calls, "add")
+	return s.pr, nil
+}
+
+func (s *fakeService) RemoveShadowReviewer(ctx context.Context, prID, userID string) (*model.PullRequest, error) {
+	s.calls = append(s.calls, "remove")
+	return s.pr, nil
+}
+
+func (s *fakeService) ReassignReviewer(ctx context.Context, prID, oldUserID string) (*model.PullRequest, string, error) {
+	s.calls = append(s.calls, "reassign")
+	return s.pr, "other", nil
+}
+
+func (s *fakeService) SetUserAbsence(ctx context.Context, userID string, from, until *time.Time) (*model.User, error) {
+	s.calls = append(s.calls, "absence:"+userID)
+	return &model.User{UserID: userID, AbsentFrom: from, AbsentUntil: until}, nil
+}
+
+// RevokeAPIToken знает один токен 1 пользователя alice и, как репозиторий, фильтрует по владельцу
+func (s *fakeService) RevokeAPIToken(ctx context.Context, tokenID int64, ownerID string) error {
+	if tokenID != 1 || (ownerID != "" && ownerID != "alice") {
+		return service.NewBusinessError("NOT_FOUND", "token not found", nil)
+	}
+	s.calls = append(s.calls, "revoke")
+	return nil
+}
+
+func do(t *testing.T, h http.Handler, token, path, body string) int {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestPullRequestReviewerMutationsAuthorization(t *testing.T) {
+	cases := []struct {
+		name, token, path, body string
+		want                    int
+	}{
+		{"reassign by author", "author:write", "/pullRequest/reassign", `{"pull_request_id":"pr-1","old_user_id":"reviewer"}`, http.StatusOK},
+		{"reassign by the reviewer", "reviewer:write", "/pullRequest/reassign", `{"pull_request_id":"pr-1","old_user_id":"reviewer"}`, http.StatusOK},
+		{"reassign by admin", "boss:admin", "/pullRequest/reassign", `{"pull_request_id":"pr-1","old_user_id":"reviewer"}`, http.StatusOK},
+		{"reassign by stranger", "mallory:write", "/pullRequest/reassign", `{"pull_request_id":"pr-1","old_user_id":"reviewer"}`, http.StatusForbidden},
+		{"remove shadow by trainee", "trainee:write", "/pullRequest/removeShadowReviewer", `{"pull_request_id":"pr-1","user_id":"trainee"}`, http.StatusOK},
+		{"remove shadow by stranger", "mallory:write", "/pullRequest/removeShadowReviewer", `{"pull_request_id":"pr-1","user_id":"trainee"}`, http.StatusForbidden},
+		{"add shadow by author", "author:write", "/pullRequest/addShadowReviewer", `{"pull_request_id":"pr-1","user_id":"trainee2"}`, http.StatusOK},
+		{"add shadow by the shadow", "trainee2:write", "/pullRequest/addShadowReviewer", `{"pull_request_id":"pr-1","user_id":"trainee2"}`, http.StatusForbidden},
+		{"add shadow by stranger", "mallory:write", "/pullRequest/addShadowReviewer", `{"pull_request_id":"pr-1","user_id":"trainee2"}`, http.StatusForbidden},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := newFakeService()
+			h := NewHandler(svc, config.IntegrationsConfig{}, nil)
+			if got := do(t, h, tc.token, tc.path, tc.body); got != tc.want {
+				t.Fatalf("status = %d, want %d", got, tc.want)
+			}
+			if tc.want == http.StatusForbidden && len(svc.calls) > 0 {
+				t.Fatalf("service called despite 403: %v", svc.calls)
+			}
+		})
+	}
+}
+
+func TestSetUserAbsenceAuthorization(t *testing.T) {
+	cases := []struct {
+		name, token, body string
+		want              int
+		call              string
+	}{
+		{"own absence", "alice:write", `{"user_id":"alice","absent_until":"2030-01-01T00:00:00Z"}`, http.StatusOK, "absence:alice"},
+		{"own absence by default", "alice:write", `{"absent_until":"2030-01-01T00:00:00Z"}`, http.StatusOK, "absence:alice"},
+		{"admin for another user", "boss:admin", `{"user_id":"alice","absent_until":"2030-01-01T00:00:00Z"}`, http.StatusOK, "absence:alice"},
+		{"another user's absence", "mallory:write", `{"user_id":"alice","absent_until":"2030-01-01T00:00:00Z"}`, http.StatusForbidden, ""},
+		{"read scope", "alice:read", `{"user_id":"alice"}`, http.StatusForbidden, ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := newFakeService()
+			h := NewHandler(svc, config.IntegrationsConfig{}, nil)
+			if got := do(t, h, tc.token, "/users/setAbsence", tc.body); got != tc.want {
+				t.Fatalf("status = %d, want %d", got, tc.want)
+			}
+			if tc.call == "" && len(svc.calls) > 0 {
+				t.Fatalf("service called despite 403: %v", svc.calls)
+			}
+			if tc.call != "" && (len(svc.calls) != 1 || svc.calls[0] != tc.call) {
+				t.Fatalf("calls = %v, want [%s]", svc.calls, tc.call)
+			}
+		})
+	}
+}
+
+func TestRevokeTokenDoesNotRevealOtherUsersTokens(t *testing.T) {
+	cases := []struct {
+		name, token, body string
+		want              int
+	}{
+		{"own token", "alice:write", `{"token_id":1}`, http.StatusOK},
+		{"admin revokes any token", "boss:admin", `{"token_id":1}`, http.StatusOK},
+		{"another user's token", "mallory:write", `{"token_id":1}`, http.StatusNotFound},
+		{"missing token", "mallory:write", `{"token_id":2}`, http.StatusNotFound},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := newFakeService()
+			h := NewHandler(svc, config.IntegrationsConfig{}, nil)
+			if got := do(t, h, tc.token, "/tokens/revoke", tc.body); got != tc.want {
+				t.Fatalf("status = %d, want %d", got, tc.want)
+			}
+			if revoked := len(svc.calls) == 1; revoked != (tc.want == http.StatusOK) {
+				t.Fatalf("revoked = %v for status %d", revoked, tc.want)
+			}
+		})
+	}
+}
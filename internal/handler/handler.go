@@ -2,38 +2,115 @@ package handler
 
 import (
 	"encoding/json"
+	"log/slog"
 	"net/http"
+	"review-service/internal/audit"
+	"review-service/internal/logging"
+	"review-service/internal/metrics"
 	"review-service/internal/model"
+	"review-service/internal/oidc"
 	"review-service/internal/service"
+	"review-service/internal/tracing"
+	"review-service/pkg/config"
+	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 )
 
 type Handler struct {
-	service service.Service
+	service      service.Service
+	integrations config.IntegrationsConfig
+	oidc         *oidc.Verifier
 }
 
-func NewHandler(service service.Service) http.Handler {
-	h := &Handler{service: service}
+// NewHandler собирает роутер. verifier может быть nil — тогда принимаются только API-токены.
+func NewHandler(service service.Service, integrations config.IntegrationsConfig, verifier *oidc.Verifier) http.Handler {
+	h := &Handler{service: service, integrations: integrations, oidc: verifier}
 	
 	r := chi.NewRouter()
+	r.Use(logging.RequestIDMiddleware, tracing.Middleware, logging.AccessLog, metrics.Middleware)
 	
-	// Teams endpoints
-	r.Post("/team/add", h.createTeam)
-	r.Get("/team/get", h.getTeam)
-	
-	// Users endpoints  
-	r.Post("/users/setIsActive", h.setUserActive)
-	r.Get("/users/getReview", h.getUserReviewRequests)
-	
-	// PullRequests endpoints
-	r.Post("/pullRequest/create", h.createPullRequest)
-	r.Post("/pullRequest/merge", h.mergePullRequest)
-	r.Post("/pullRequest/reassign", h.reassignReviewer)
-	
-	// Health check
+	// Вебхуки и SCIM проверяют собственные секреты, health и metrics открыты
+	r.With(auditAs(audit.ActorWebhook, "github")).Post("/integrations/github/webhook", h.githubWebhook)
+	r.With(auditAs(audit.ActorWebhook, "gitlab")).Post("/integrations/gitlab/webhook", h.gitlabWebhook)
+	r.Route("/scim/v2", h.scimRoutes)
 	r.Get("/health", h.healthCheck)
-	
+	r.Handle("/metrics", metrics.Handler())
+
+	r.Group(func(r chi.Router) {
+		r.Use(h.authenticate)
+
+		// read
+		r.Group(func(r chi.Router) {
+			r.Use(requireScope(model.ScopeRead))
+
+			r.Get("/team/get", h.getTeam)
+			r.Get("/team/list", h.listTeams)
+			r.Get("/team/tree", h.getTeamTree)
+
+			r.Get("/users/get", h.getUser)
+			r.Get("/users/list", h.listUsers)
+			r.Get("/users/getReview", h.getUserReviewRequests)
+			r.Get("/users/notifications/get", h.getNotificationPreferences)
+
+			r.Get("/pullRequest/get", h.getPullRequest)
+			r.Get("/pullRequest/history", h.getPullRequestHistory)
+
+			r.Get("/stats/reviewers", h.getReviewerStats)
+			r.Get("/stats/teams", h.getTeamStats)
+
+			r.Get("/tokens/list", h.listTokens)
+		})
+
+		// write: действия с PR проверяют ещё и автора, настройки и токены — владельца
+		r.Group(func(r chi.Router) {
+			r.Use(requireScope(model.ScopeWrite))
+
+			r.Post("/users/notifications/set", h.setNotificationPreference)
+			r.Post("/users/notifications/delete", h.deleteNotificationPreference)
+			r.Post("/users/setAbsence", h.setUserAbsence)
+
+			r.Post("/pullRequest/create", h.createPullRequest)
+			r.Post("/pullRequest/merge", h.mergePullRequest)
+			r.Post("/pullRequest/reassign", h.reassignReviewer)
+			r.Post("/pullRequest/verdict", h.submitVerdict)
+			r.Post("/pullRequest/close", h.closePullRequest)
+			r.Post("/pullRequest/reopen", h.reopenPullRequest)
+			r.Post("/pullRequest/addShadowReviewer", h.addShadowReviewer)
+			r.Post("/pullRequest/removeShadowReviewer", h.removeShadowReviewer)
+
+			r.Post("/tokens/issue", h.issueToken)
+			r.Post("/tokens/revoke", h.revokeToken)
+		})
+
+		// admin
+		r.Group(func(r chi.Router) {
+			r.Use(requireScope(model.ScopeAdmin))
+
+			r.Post("/team/add", h.createTeam)
+			r.Post("/team/addMember", h.addTeamMember)
+			r.Post("/team/removeMember", h.removeTeamMember)
+			r.Post("/team/setParent", h.setTeamParent)
+			r.Post("/team/setEscalation", h.setTeamEscalation)
+			r.Post("/team/setReviewerRoles", h.setTeamReviewerRoles)
+			r.Post("/team/setMemberRole", h.setMemberRole)
+			r.Post("/team/setMentorship", h.setTeamMentorship)
+			r.Post("/team/rename", h.renameTeam)
+			r.Post("/team/delete", h.deleteTeam)
+
+			r.Post("/users/update", h.updateUser)
+			r.Post("/users/setIsActive", h.setUserActive)
+			r.Post("/users/moveTeam", h.moveUserToTeam)
+
+			r.Post("/integrations/identities/link", h.linkUserIdentity)
+
+			r.Post("/admin/import", h.importTeams)
+			r.Get("/admin/export", h.exportTeams)
+			r.Get("/audit", h.listAuditLog)
+		})
+	})
+
 	return r
 }
 
@@ -45,19 +122,22 @@ func (h *Handler) healthCheck(w http.ResponseWriter, r *http.Request) {
 
 // Teams handlers
 func (h *Handler) createTeam(w http.ResponseWriter, r *http.Request) {
-	var req model.Team
+	var req struct {
+		model.Team
+		ConflictPolicy string `json:"conflict_policy"`
+	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
 		return
 	}
 
-	team, err := h.service.CreateTeam(r.Context(), &req)
+	creation, err := h.service.CreateTeam(r.Context(), &req.Team, req.ConflictPolicy)
 	if err != nil {
-		handleServiceError(w, err)
+		handleServiceError(w, r, err)
 		return
 	}
 
-	writeJSON(w, http.StatusCreated, map[string]interface{}{"team": team})
+	writeJSON(w, http.StatusCreated, creation)
 }
 
 func (h *Handler) getTeam(w http.ResponseWriter, r *http.Request) {
@@ -69,14 +149,295 @@ func (h *Handler) getTeam(w http.ResponseWriter, r *http.Request) {
 
 	team, err := h.service.GetTeam(r.Context(), teamName)
 	if err != nil {
-		handleServiceError(w, err)
+		handleServiceError(w, r, err)
 		return
 	}
 
 	writeJSON(w, http.StatusOK, team)
 }
 
+func (h *Handler) addTeamMember(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TeamName string `json:"team_name"`
+		model.TeamMember
+	}
+	
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	team, err := h.service.AddTeamMember(r.Context(), req.TeamName, &req.TeamMember)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"team": team})
+}
+
+func (h *Handler) removeTeamMember(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TeamName      string `json:"team_name"`
+		UserID        string `json:"user_id"`
+		ReviewsPolicy string `json:"reviews_policy"`
+	}
+	
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	change, err := h.service.RemoveTeamMember(r.Context(), req.TeamName, req.UserID, req.ReviewsPolicy)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, change)
+}
+
+func (h *Handler) setTeamParent(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TeamName   string `json:"team_name"`
+		ParentTeam string `json:"parent_team"`
+	}
+	
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	team, err := h.service.SetTeamParent(r.Context(), req.TeamName, req.ParentTeam)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"team": team})
+}
+
+func (h *Handler) setTeamEscalation(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TeamName   string `json:"team_name"`
+		Escalation string `json:"escalation"`
+	}
+	
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	team, err := h.service.SetTeamEscalation(r.Context(), req.TeamName, req.Escalation)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"team": team})
+}
+
+func (h *Handler) setTeamReviewerRoles(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TeamName              string   `json:"team_name"`
+		RequiredReviewerRoles []string `json:"required_reviewer_roles"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	team, err := h.service.SetTeamReviewerRoles(r.Context(), req.TeamName, req.RequiredReviewerRoles)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"team": team})
+}
+
+func (h *Handler) setMemberRole(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TeamName string `json:"team_name"`
+		UserID   string `json:"user_id"`
+		Role     string `json:"role"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	team, err := h.service.SetMemberRole(r.Context(), req.TeamName, req.UserID, req.Role)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"team": team})
+}
+
+func (h *Handler) setTeamMentorship(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TeamName string `json:"team_name"`
+		Enabled  bool   `json:"enabled"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	team, err := h.service.SetTeamMentorship(r.Context(), req.TeamName, req.Enabled)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"team": team})
+}
+
+func (h *Handler) getTeamTree(w http.ResponseWriter, r *http.Request) {
+	tree, err := h.service.GetTeamTree(r.Context(), r.URL.Query().Get("team_name"))
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"teams": tree})
+}
+
+func (h *Handler) listTeams(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := model.TeamListFilter{SortBy: query.Get("sort")}
+
+	switch query.Get("order") {
+	case "", "asc":
+	case "desc":
+		filter.Descending = true
+	default:
+		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Invalid order parameter"))
+		return
+	}
+
+	var ok bool
+	if filter.Limit, filter.Offset, ok = pageParams(w, r); !ok {
+		return
+	}
+
+	teams, err := h.service.ListTeams(r.Context(), filter)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, teams)
+}
+
+func (h *Handler) renameTeam(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TeamName    string `json:"team_name"`
+		NewTeamName string `json:"new_team_name"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	team, err := h.service.RenameTeam(r.Context(), req.TeamName, req.NewTeamName)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"team": team})
+}
+
+func (h *Handler) deleteTeam(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TeamName         string `json:"team_name"`
+		DestinationTeam  string `json:"destination_team"`
+		ReparentSubTeams bool   `json:"reparent_subteams"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	if err := h.service.DeleteTeam(r.Context(), req.TeamName, req.DestinationTeam, req.ReparentSubTeams); err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"team_name": req.TeamName, "deleted": true})
+}
+
 // Users handlers
+func (h *Handler) getUser(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Missing user_id parameter"))
+		return
+	}
+
+	user, err := h.service.GetUser(r.Context(), userID)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"user": user})
+}
+
+func (h *Handler) listUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := model.UserFilter{
+		TeamName:   query.Get("team_name"),
+		NamePrefix: query.Get("name_prefix"),
+	}
+
+	if value := query.Get("is_active"); value != "" {
+		isActive, err := strconv.ParseBool(value)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Invalid is_active parameter"))
+			return
+		}
+		filter.IsActive = &isActive
+	}
+
+	var ok bool
+	if filter.Limit, filter.Offset, ok = pageParams(w, r); !ok {
+		return
+	}
+
+	users, err := h.service.ListUsers(r.Context(), filter)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, users)
+}
+
+func (h *Handler) updateUser(w http.ResponseWriter, r *http.Request) {
+	var req model.UserUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	user, err := h.service.UpdateUser(r.Context(), &req)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"user": user})
+}
+
 func (h *Handler) setUserActive(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		UserID   string `json:"user_id"`
@@ -90,13 +451,63 @@ func (h *Handler) setUserActive(w http.ResponseWriter, r *http.Request) {
 
 	user, err := h.service.SetUserActive(r.Context(), req.UserID, req.IsActive)
 	if err != nil {
-		handleServiceError(w, err)
+		handleServiceError(w, r, err)
 		return
 	}
 
 	writeJSON(w, http.StatusOK, map[string]interface{}{"user": user})
 }
 
+// setUserAbsence задаёт период отсутствия; чужое отсутствие может менять только администратор
+func (h *Handler) setUserAbsence(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		UserID      string     `json:"user_id"`
+		AbsentFrom  *time.Time `json:"absent_from"`
+		AbsentUntil *time.Time `json:"absent_until"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	if req.UserID == "" {
+		req.UserID = principal(r).UserID
+	}
+	if !authorizeUser(w, r, req.UserID) {
+		return
+	}
+
+	user, err := h.service.SetUserAbsence(r.Context(), req.UserID, req.AbsentFrom, req.AbsentUntil)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"user": user})
+}
+
+func (h *Handler) moveUserToTeam(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		UserID        string `json:"user_id"`
+		TeamName      string `json:"team_name"`
+		ReviewsPolicy string `json:"reviews_policy"`
+	}
+	
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	change, err := h.service.MoveUserToTeam(r.Context(), req.UserID, req.TeamName, req.ReviewsPolicy)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, change)
+}
+
 func (h *Handler) getUserReviewRequests(w http.ResponseWriter, r *http.Request) {
 	userID := r.URL.Query().Get("user_id")
 	if userID == "" {
@@ -106,7 +517,7 @@ func (h *Handler) getUserReviewRequests(w http.ResponseWriter, r *http.Request)
 
 	prs, err := h.service.GetUserReviewRequests(r.Context(), userID)
 	if err != nil {
-		handleServiceError(w, err)
+		handleServiceError(w, r, err)
 		return
 	}
 
@@ -129,9 +540,13 @@ func (h *Handler) createPullRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !authorizeUser(w, r, req.AuthorID) {
+		return
+	}
+
 	pr, err := h.service.CreatePullRequest(r.Context(), req.PullRequestID, req.PullRequestName, req.AuthorID)
 	if err != nil {
-		handleServiceError(w, err)
+		handleServiceError(w, r, err)
 		return
 	}
 
@@ -148,9 +563,107 @@ func (h *Handler) mergePullRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.authorizePullRequestAuthor(w, r, req.PullRequestID) {
+		return
+	}
+
 	pr, err := h.service.MergePullRequest(r.Context(), req.PullRequestID)
 	if err != nil {
-		handleServiceError(w, err)
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"pr": pr})
+}
+
+func (h *Handler) closePullRequest(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		PullRequestID string `json:"pull_request_id"`
+	}
+	
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	if !h.authorizePullRequestAuthor(w, r, req.PullRequestID) {
+		return
+	}
+
+	pr, err := h.service.ClosePullRequest(r.Context(), req.PullRequestID)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"pr": pr})
+}
+
+func (h *Handler) addShadowReviewer(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		PullRequestID string `json:"pull_request_id"`
+		UserID        string `json:"user_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	if !h.authorizePullRequestAuthor(w, r, req.PullRequestID) {
+		return
+	}
+
+	pr, err := h.service.AddShadowReviewer(r.Context(), req.PullRequestID, req.UserID)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"pr": pr})
+}
+
+func (h *Handler) removeShadowReviewer(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		PullRequestID string `json:"pull_request_id"`
+		UserID        string `json:"user_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	if !h.authorizePullRequestReviewer(w, r, req.PullRequestID, req.UserID) {
+		return
+	}
+
+	pr, err := h.service.RemoveShadowReviewer(r.Context(), req.PullRequestID, req.UserID)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"pr": pr})
+}
+
+func (h *Handler) reopenPullRequest(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		PullRequestID string `json:"pull_request_id"`
+	}
+	
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	if !h.authorizePullRequestAuthor(w, r, req.PullRequestID) {
+		return
+	}
+
+	pr, err := h.service.ReopenPullRequest(r.Context(), req.PullRequestID)
+	if err != nil {
+		handleServiceError(w, r, err)
 		return
 	}
 
@@ -168,9 +681,13 @@ func (h *Handler) reassignReviewer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.authorizePullRequestReviewer(w, r, req.PullRequestID, req.OldUserID) {
+		return
+	}
+
 	pr, newUserID, err := h.service.ReassignReviewer(r.Context(), req.PullRequestID, req.OldUserID)
 	if err != nil {
-		handleServiceError(w, err)
+		handleServiceError(w, r, err)
 		return
 	}
 
@@ -180,6 +697,27 @@ func (h *Handler) reassignReviewer(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// submitVerdict записывает вердикт вызывающего: оставить вердикт за другого нельзя, даже администратору
+func (h *Handler) submitVerdict(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		PullRequestID string `json:"pull_request_id"`
+		Verdict       string `json:"verdict"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	pr, err := h.service.SubmitVerdict(r.Context(), req.PullRequestID, principal(r).UserID, req.Verdict)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"pr": pr})
+}
+
 // Вспомогательные функции
 func writeJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
@@ -187,13 +725,33 @@ func writeJSON(w http.ResponseWriter, status int, data interface{}) {
 	json.NewEncoder(w).Encode(data)
 }
 
+// pageParams читает limit и offset из query; при ошибке сам отвечает 400
+func pageParams(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	var limit, offset int
+	for name, target := range map[string]*int{"limit": &limit, "offset": &offset} {
+		value := r.URL.Query().Get(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Invalid "+name+" parameter"))
+			return 0, 0, false
+		}
+		*target = parsed
+	}
+	return limit, offset, true
+}
+
 func writeError(w http.ResponseWriter, status int, errorResp model.ErrorResponse) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(errorResp)
 }
 
-func handleServiceError(w http.ResponseWriter, err error) {
+// handleServiceError переводит ошибку сервиса в HTTP-ответ. Причину внутренних ошибок клиент
+// не видит, поэтому она пишется в лог вместе с идентификатором запроса.
+func handleServiceError(w http.ResponseWriter, r *http.Request, err error) {
 	if businessErr, ok := err.(service.BusinessError); ok {
 		switch businessErr.Code {
 		case "TEAM_EXISTS":
@@ -202,10 +760,28 @@ func handleServiceError(w http.ResponseWriter, err error) {
 			writeError(w, http.StatusConflict, model.NewErrorResponse("PR_EXISTS", businessErr.Message))
 		case "PR_MERGED":
 			writeError(w, http.StatusConflict, model.NewErrorResponse("PR_MERGED", businessErr.Message))
+		case "PR_CLOSED":
+			writeError(w, http.StatusConflict, model.NewErrorResponse("PR_CLOSED", businessErr.Message))
 		case "NOT_ASSIGNED":
 			writeError(w, http.StatusConflict, model.NewErrorResponse("NOT_ASSIGNED", businessErr.Message))
 		case "NO_CANDIDATE":
 			writeError(w, http.StatusConflict, model.NewErrorResponse("NO_CANDIDATE", businessErr.Message))
+		case "USER_EXISTS":
+			writeError(w, http.StatusConflict, model.NewErrorResponse("USER_EXISTS", businessErr.Message))
+		case "TEAM_CYCLE":
+			writeError(w, http.StatusConflict, model.NewErrorResponse("TEAM_CYCLE", businessErr.Message))
+		case "USER_IN_OTHER_TEAM":
+			writeError(w, http.StatusConflict, model.NewErrorResponse("USER_IN_OTHER_TEAM", businessErr.Message))
+		case "HAS_OPEN_REVIEWS":
+			writeError(w, http.StatusConflict, model.NewErrorResponse("HAS_OPEN_REVIEWS", businessErr.Message))
+		case "TEAM_HAS_SUBTEAMS":
+			writeError(w, http.StatusConflict, model.NewErrorResponse("TEAM_HAS_SUBTEAMS", businessErr.Message))
+		case "TEAM_HAS_OPEN_PRS":
+			writeError(w, http.StatusConflict, model.NewErrorResponse("TEAM_HAS_OPEN_PRS", businessErr.Message))
+		case "UNAUTHORIZED":
+			writeUnauthorized(w, businessErr.Message)
+		case "FORBIDDEN":
+			writeError(w, http.StatusForbidden, model.NewErrorResponse("FORBIDDEN", businessErr.Message))
 		case "NOT_FOUND":
 			writeError(w, http.StatusNotFound, model.NewErrorResponse("NOT_FOUND", businessErr.Message))
 		default:
@@ -215,5 +791,9 @@ func handleServiceError(w http.ResponseWriter, err error) {
 	}
 	
 	// Общая ошибка сервера
+	logInternalError(r, err)
 	writeError(w, http.StatusInternalServerError, model.NewErrorResponse("INTERNAL_ERROR", "Internal server error"))
-}
\ No newline at end of file
+}
+func logInternalError(r *http.Request, err error) {
+	slog.ErrorContext(r.Context(), "internal error", "method", r.Method, "path", r.URL.Path, "error", err)
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"net/http"
+	"review-service/internal/model"
+)
+
+// getPullRequest отдаёт PR; с as_of (RFC 3339 или YYYY-MM-DD) — его статус и ревьюеров на тот момент,
+// восстановленные по истории
+func (h *Handler) getPullRequest(w http.ResponseWriter, r *http.Request) {
+	prID := r.URL.Query().Get("pull_request_id")
+	if prID == "" {
+		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Missing pull_request_id parameter"))
+		return
+	}
+
+	var pr *model.PullRequest
+	var err error
+	if value := r.URL.Query().Get("as_of"); value != "" {
+		asOf, parseErr := parseTimeParam(value)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Invalid as_of parameter"))
+			return
+		}
+		pr, err = h.service.GetPullRequestAsOf(r.Context(), prID, asOf)
+	} else {
+		pr, err = h.service.GetPullRequest(r.Context(), prID)
+	}
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"pr": pr})
+}
+
+func (h *Handler) getPullRequestHistory(w http.ResponseWriter, r *http.Request) {
+	prID := r.URL.Query().Get("pull_request_id")
+	if prID == "" {
+		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Missing pull_request_id parameter"))
+		return
+	}
+
+	history, err := h.service.GetPullRequestHistory(r.Context(), prID)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, history)
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"review-service/internal/integration/github"
+	"review-service/internal/integration/gitlab"
+	"review-service/internal/model"
+)
+
+const maxWebhookBodySize = 5 << 20
+
+func (h *Handler) linkUserIdentity(w http.ResponseWriter, r *http.Request) {
+	var req model.UserIdentity
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	identity, err := h.service.LinkUserIdentity(r.Context(), &req)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"identity": identity})
+}
+
+func (h *Handler) githubWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodySize))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	if !github.VerifySignature(h.integrations.GitHubWebhookSecret, body, r.Header.Get(github.HeaderSignature)) {
+		writeError(w, http.StatusUnauthorized, model.NewErrorResponse("UNAUTHORIZED", "Invalid webhook signature"))
+		return
+	}
+
+	deliveryID := r.Header.Get(github.HeaderDelivery)
+	if r.Header.Get(github.HeaderEvent) != "pull_request" {
+		writeJSON(w, http.StatusOK, model.WebhookResult{DeliveryID: deliveryID, Status: "ignored"})
+		return
+	}
+	if deliveryID == "" {
+		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Missing delivery id"))
+		return
+	}
+
+	event, err := github.ParsePullRequestEvent(body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Invalid pull_request payload"))
+		return
+	}
+	if event == nil {
+		writeJSON(w, http.StatusOK, model.WebhookResult{DeliveryID: deliveryID, Status: "ignored"})
+		return
+	}
+	event.DeliveryID = deliveryID
+
+	h.handlePullRequestEvent(w, r, event)
+}
+
+func (h *Handler) gitlabWebhook(w http.ResponseWriter, r *http.Request) {
+	if !gitlab.VerifyToken(h.integrations.GitLabWebhookToken, r.Header.Get(gitlab.HeaderToken)) {
+		writeError(w, http.StatusUnauthorized, model.NewErrorResponse("UNAUTHORIZED", "Invalid webhook token"))
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodySize))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	deliveryID := r.Header.Get(gitlab.HeaderDelivery)
+	if r.Header.Get(gitlab.HeaderEvent) != gitlab.EventMergeRequest {
+		writeJSON(w, http.StatusOK, model.WebhookResult{DeliveryID: deliveryID, Status: "ignored"})
+		return
+	}
+	if deliveryID == "" {
+		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Missing delivery id"))
+		return
+	}
+
+	event, err := gitlab.ParseMergeRequestEvent(body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Invalid merge request payload"))
+		return
+	}
+	if event == nil {
+		writeJSON(w, http.StatusOK, model.WebhookResult{DeliveryID: deliveryID, Status: "ignored"})
+		return
+	}
+	event.DeliveryID = deliveryID
+
+	h.handlePullRequestEvent(w, r, event)
+}
+
+func (h *Handler) handlePullRequestEvent(w http.ResponseWriter, r *http.Request, event *model.PullRequestEvent) {
+	result, err := h.service.HandlePullRequestEvent(r.Context(), event)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"review-service/internal/model"
+)
+
+func (h *Handler) setNotificationPreference(w http.ResponseWriter, r *http.Request) {
+	var req model.NotificationPreferenceUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	if !authorizeUser(w, r, req.UserID) {
+		return
+	}
+
+	pref, err := h.service.SetNotificationPreference(r.Context(), &req)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"preference": pref})
+}
+
+func (h *Handler) deleteNotificationPreference(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		UserID  string `json:"user_id"`
+		Channel string `json:"channel"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	if !authorizeUser(w, r, req.UserID) {
+		return
+	}
+
+	if err := h.service.DeleteNotificationPreference(r.Context(), req.UserID, req.Channel); err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (h *Handler) getNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Missing user_id parameter"))
+		return
+	}
+
+	if !authorizeUser(w, r, userID) {
+		return
+	}
+
+	prefs, err := h.service.GetNotificationPreferences(r.Context(), userID)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"user_id":     userID,
+		"preferences": prefs,
+	})
+}
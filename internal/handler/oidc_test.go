@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"review-service/internal/model"
+	"review-service/internal/oidc"
+	"review-service/pkg/config"
+	"testing"
+	"time"
+)
+
+func TestOIDCAuthentication(t *testing.T) {
+	dir := t.TempDir()
+	keyPath, jwksPath := filepath.Join(dir, "stub.pem"), filepath.Join(dir, "stub.jwks.json")
+	if err := oidc.GenerateStubKey(keyPath, jwksPath); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.OIDCConfig{JWKSFile: jwksPath, Issuer: "https://idp.example.com", Audience: "review-service", GroupsClaim: "groups"}
+	verifier, err := oidc.NewVerifier(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svc := newFakeService()
+	svc.users["author"] = &model.User{UserID: "author", IsActive: true}
+	svc.users["former"] = &model.User{UserID: "former", IsActive: false}
+	h := NewHandler(svc, config.IntegrationsConfig{}, verifier)
+
+	body := `{"pull_request_id":"pr-1","old_user_id":"reviewer"}`
+	for _, tc := range []struct {
+		name, subject string
+		want          int
+	}{
+		{"active user", "author", http.StatusOK},
+		{"sub is not a user", "ghost", http.StatusUnauthorized},
+		{"inactive user", "former", http.StatusUnauthorized},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			token, err := oidc.IssueStubToken(keyPath, cfg, tc.subject, nil, nil, time.Hour)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := do(t, h, token, "/pullRequest/reassign", body); got != tc.want {
+				t.Fatalf("status = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,630 @@
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"review-service/internal/audit"
+	"review-service/internal/model"
+	"review-service/internal/service"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// SCIM 2.0 (RFC 7643/7644) для провижининга из IdP. Пользователь SCIM — это user_id (userName и id)
+// с displayName = username; группа — команда (id и displayName = team_name). Удаление пользователя
+// и active=false деактивируют его, добавление в группу делает команду основной, как /users/moveTeam.
+
+const (
+	scimUserSchema  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	scimGroupSchema = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	scimListSchema  = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	scimErrorSchema = "urn:ietf:params:scim:api:messages:2.0:Error"
+	scimContentType = "application/scim+json"
+)
+
+type scimMeta struct {
+	ResourceType string `json:"resourceType"`
+	Location     string `json:"location"`
+}
+
+type scimName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+type scimRef struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+}
+
+type scimUser struct {
+	Schemas     []string  `json:"schemas"`
+	ID          string    `json:"id,omitempty"`
+	UserName    string    `json:"userName"`
+	DisplayName string    `json:"displayName,omitempty"`
+	Name        *scimName `json:"name,omitempty"`
+	Active      *bool     `json:"active,omitempty"`
+	Groups      []scimRef `json:"groups,omitempty"`
+	Meta        *scimMeta `json:"meta,omitempty"`
+}
+
+type scimGroup struct {
+	Schemas     []string  `json:"schemas"`
+	ID          string    `json:"id,omitempty"`
+	DisplayName string    `json:"displayName"`
+	Members     []scimRef `json:"members"`
+	Meta        *scimMeta `json:"meta,omitempty"`
+}
+
+type scimListResponse struct {
+	Schemas      []string    `json:"schemas"`
+	TotalResults int         `json:"totalResults"`
+	StartIndex   int         `json:"startIndex"`
+	ItemsPerPage int         `json:"itemsPerPage"`
+	Resources    interface{} `json:"Resources"`
+}
+
+type scimPatchRequest struct {
+	Operations []scimPatchOperation `json:"Operations"`
+}
+
+type scimPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+var (
+	scimFilterPattern       = regexp.MustCompile(`(?i)^\s*([a-z.]+)\s+eq\s+(?:"((?:[^"\\]|\\.)*)"|(true|false))\s*$`)
+	scimMemberFilterPattern = regexp.MustCompile(`(?i)^members\[value eq "((?:[^"\\]|\\.)*)"\]$`)
+)
+
+func (h *Handler) scimRoutes(r chi.Router) {
+	r.Use(h.scimAuth, auditAs(audit.ActorSCIM, "scim"))
+
+	r.Get("/Users", h.scimListUsers)
+	r.Post("/Users", h.scimCreateUser)
+	r.Get("/Users/{id}", h.scimGetUser)
+	r.Patch("/Users/{id}", h.scimPatchUser)
+	r.Delete("/Users/{id}", h.scimDeleteUser)
+
+	r.Get("/Groups", h.scimListGroups)
+	r.Post("/Groups", h.scimCreateGroup)
+	r.Get("/Groups/{id}", h.scimGetGroup)
+	r.Patch("/Groups/{id}", h.scimPatchGroup)
+	r.Delete("/Groups/{id}", h.scimDeleteGroup)
+}
+
+func (h *Handler) scimAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if h.integrations.SCIMToken == "" ||
+			subtle.ConstantTimeCompare([]byte(token), []byte(h.integrations.SCIMToken)) != 1 {
+			writeSCIMError(w, http.StatusUnauthorized, "", "Invalid SCIM token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (h *Handler) scimListUsers(w http.ResponseWriter, r *http.Request) {
+	startIndex, count, ok := scimPage(w, r)
+	if !ok {
+		return
+	}
+
+	filter := model.UserFilter{Limit: count, Offset: startIndex - 1}
+	if expression := r.URL.Query().Get("filter"); expression != "" {
+		attribute, value, ok := parseSCIMFilter(expression)
+		switch {
+		case ok && attribute == "username":
+			user, err := h.service.GetUser(r.Context(), value)
+			users := []*model.User{}
+			if err == nil {
+				users = append(users, user)
+			} else if !isNotFound(err) {
+				writeSCIMServiceError(w, r, err)
+				return
+			}
+			writeSCIMUserList(w, users, len(users), 1)
+			return
+		case ok && attribute == "active":
+			isActive := value == "true"
+			filter.IsActive = &isActive
+		default:
+			writeSCIMError(w, http.StatusBadRequest, "invalidFilter", "Supported filters: userName eq, active eq")
+			return
+		}
+	}
+
+	list, err := h.service.ListUsers(r.Context(), filter)
+	if err != nil {
+		writeSCIMServiceError(w, r, err)
+		return
+	}
+
+	writeSCIMUserList(w, list.Users, list.Total, startIndex)
+}
+
+func (h *Handler) scimCreateUser(w http.ResponseWriter, r *http.Request) {
+	var req scimUser
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeSCIMError(w, http.StatusBadRequest, "invalidSyntax", "Invalid request body")
+		return
+	}
+	if req.UserName == "" {
+		writeSCIMError(w, http.StatusBadRequest, "invalidValue", "userName is required")
+		return
+	}
+
+	user := &model.User{UserID: req.UserName, Username: scimDisplayName(&req), IsActive: req.Active == nil || *req.Active}
+	created, err := h.service.CreateUser(r.Context(), user)
+	if err != nil {
+		writeSCIMServiceError(w, r, err)
+		return
+	}
+
+	writeSCIM(w, http.StatusCreated, toSCIMUser(created))
+}
+
+func (h *Handler) scimGetUser(w http.ResponseWriter, r *http.Request) {
+	user, err := h.service.GetUser(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeSCIMServiceError(w, r, err)
+		return
+	}
+
+	writeSCIM(w, http.StatusOK, toSCIMUser(user))
+}
+
+func (h *Handler) scimPatchUser(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "id")
+
+	var req scimPatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeSCIMError(w, http.StatusBadRequest, "invalidSyntax", "Invalid request body")
+		return
+	}
+
+	update := &model.UserUpdate{UserID: userID}
+	for _, operation := range req.Operations {
+		if !strings.EqualFold(operation.Op, "replace") && !strings.EqualFold(operation.Op, "add") {
+			writeSCIMError(w, http.StatusBadRequest, "invalidValue", "Unsupported operation "+operation.Op)
+			return
+		}
+
+		values := map[string]json.RawMessage{}
+		if operation.Path == "" {
+			if err := json.Unmarshal(operation.Value, &values); err != nil {
+				writeSCIMError(w, http.StatusBadRequest, "invalidValue", "Operation without path needs an object value")
+				return
+			}
+		} else {
+			values[operation.Path] = operation.Value
+		}
+
+		// Прочие атрибуты (emails, name.* и т.п.) сервис не хранит и молча пропускает
+		for path, value := range values {
+			switch strings.ToLower(path) {
+			case "active":
+				active, err := parseSCIMBool(value)
+				if err != nil {
+					writeSCIMError(w, http.StatusBadRequest, "invalidValue", "active must be boolean")
+					return
+				}
+				update.IsActive = &active
+			case "displayname":
+				var username string
+				if err := json.Unmarshal(value, &username); err != nil {
+					writeSCIMError(w, http.StatusBadRequest, "invalidValue", "displayName must be string")
+					return
+				}
+				update.Username = &username
+			}
+		}
+	}
+
+	user, err := h.applySCIMUserUpdate(r.Context(), update)
+	if err != nil {
+		writeSCIMServiceError(w, r, err)
+		return
+	}
+
+	writeSCIM(w, http.StatusOK, toSCIMUser(user))
+}
+
+// applySCIMUserUpdate меняет активность через SetUserActive (тот же путь, что и /users/setIsActive),
+// а имя — через UpdateUser
+func (h *Handler) applySCIMUserUpdate(ctx context.Context, update *model.UserUpdate) (*model.User, error) {
+	var user *model.User
+	var err error
+	if update.IsActive != nil {
+		if user, err = h.service.SetUserActive(ctx, update.UserID, *update.IsActive); err != nil {
+			return nil, err
+		}
+	}
+	if update.Username != nil {
+		return h.service.UpdateUser(ctx, &model.UserUpdate{UserID: update.UserID, Username: update.Username})
+	}
+	if user == nil {
+		return h.service.GetUser(ctx, update.UserID)
+	}
+	return user, nil
+}
+
+// scimDeleteUser деактивирует пользователя: запись остаётся, так как на неё ссылаются PR
+func (h *Handler) scimDeleteUser(w http.ResponseWriter, r *http.Request) {
+	if _, err := h.service.SetUserActive(r.Context(), chi.URLParam(r, "id"), false); err != nil {
+		writeSCIMServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) scimListGroups(w http.ResponseWriter, r *http.Request) {
+	startIndex, count, ok := scimPage(w, r)
+	if !ok {
+		return
+	}
+
+	var teamNames []string
+	total := 0
+	if expression := r.URL.Query().Get("filter"); expression != "" {
+		attribute, value, ok := parseSCIMFilter(expression)
+		if !ok || attribute != "displayname" {
+			writeSCIMError(w, http.StatusBadRequest, "invalidFilter", "Supported filters: displayName eq")
+			return
+		}
+		teamNames = append(teamNames, value)
+		startIndex = 1
+	} else {
+		list, err := h.service.ListTeams(r.Context(), model.TeamListFilter{Limit: count, Offset: startIndex - 1})
+		if err != nil {
+			writeSCIMServiceError(w, r, err)
+			return
+		}
+		for _, team := range list.Teams {
+			teamNames = append(teamNames, team.TeamName)
+		}
+		total = list.Total
+	}
+
+	groups := []scimGroup{}
+	for _, teamName := range teamNames {
+		team, err := h.service.GetTeam(r.Context(), teamName)
+		if err != nil {
+			if isNotFound(err) {
+				continue
+			}
+			writeSCIMServiceError(w, r, err)
+			return
+		}
+		groups = append(groups, toSCIMGroup(team))
+	}
+	if r.URL.Query().Get("filter") != "" {
+		total = len(groups)
+	}
+
+	writeSCIM(w, http.StatusOK, scimListResponse{
+		Schemas:      []string{scimListSchema},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(groups),
+		Resources:    groups,
+	})
+}
+
+func (h *Handler) scimCreateGroup(w http.ResponseWriter, r *http.Request) {
+	var req scimGroup
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeSCIMError(w, http.StatusBadRequest, "invalidSyntax", "Invalid request body")
+		return
+	}
+	if req.DisplayName == "" {
+		writeSCIMError(w, http.StatusBadRequest, "invalidValue", "displayName is required")
+		return
+	}
+
+	userIDs := make([]string, 0, len(req.Members))
+	for _, member := range req.Members {
+		userIDs = append(userIDs, member.Value)
+	}
+
+	team, err := h.service.ProvisionTeam(r.Context(), req.DisplayName, userIDs)
+	if err != nil {
+		writeSCIMServiceError(w, r, err)
+		return
+	}
+
+	writeSCIM(w, http.StatusCreated, toSCIMGroup(team))
+}
+
+func (h *Handler) scimGetGroup(w http.ResponseWriter, r *http.Request) {
+	team, err := h.service.GetTeam(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeSCIMServiceError(w, r, err)
+		return
+	}
+
+	writeSCIM(w, http.StatusOK, toSCIMGroup(team))
+}
+
+// scimPatchGroup применяет изменения состава: добавление делает команду основной для пользователя,
+// удаление выводит из команды; открытые ревью в обоих случаях переназначаются
+func (h *Handler) scimPatchGroup(w http.ResponseWriter, r *http.Request) {
+	teamName := chi.URLParam(r, "id")
+
+	var req scimPatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeSCIMError(w, http.StatusBadRequest, "invalidSyntax", "Invalid request body")
+		return
+	}
+
+	team, err := h.service.GetTeam(r.Context(), teamName)
+	if err != nil {
+		writeSCIMServiceError(w, r, err)
+		return
+	}
+	members := map[string]bool{}
+	for _, member := range team.Members {
+		members[member.UserID] = true
+	}
+
+	var add, remove []string
+	for _, operation := range req.Operations {
+		op := strings.ToLower(operation.Op)
+		path := operation.Path
+
+		if path == "" && op == "replace" {
+			var value struct {
+				DisplayName string          `json:"displayName"`
+				Members     json.RawMessage `json:"members"`
+			}
+			if err := json.Unmarshal(operation.Value, &value); err != nil {
+				writeSCIMError(w, http.StatusBadRequest, "invalidValue", "Operation without path needs an object value")
+				return
+			}
+			if value.DisplayName != "" && value.DisplayName != teamName {
+				writeSCIMError(w, http.StatusBadRequest, "mutability", "displayName is immutable, use /team/rename")
+				return
+			}
+			if value.Members == nil {
+				continue
+			}
+			path, operation.Value = "members", value.Members
+		}
+
+		if match := scimMemberFilterPattern.FindStringSubmatch(path); match != nil && op == "remove" {
+			remove = append(remove, match[1])
+			continue
+		}
+
+		switch strings.ToLower(path) {
+		case "displayname":
+			var displayName string
+			if err := json.Unmarshal(operation.Value, &displayName); err != nil || displayName != teamName {
+				writeSCIMError(w, http.StatusBadRequest, "mutability", "displayName is immutable, use /team/rename")
+				return
+			}
+		case "members":
+			var refs []scimRef
+			if len(operation.Value) > 0 {
+				if err := json.Unmarshal(operation.Value, &refs); err != nil {
+					writeSCIMError(w, http.StatusBadRequest, "invalidValue", "members must be a list")
+					return
+				}
+			}
+			switch op {
+			case "add":
+				for _, ref := range refs {
+					add = append(add, ref.Value)
+				}
+			case "remove":
+				if len(refs) == 0 {
+					for userID := range members {
+						remove = append(remove, userID)
+					}
+				}
+				for _, ref := range refs {
+					remove = append(remove, ref.Value)
+				}
+			case "replace":
+				wanted := map[string]bool{}
+				for _, ref := range refs {
+					wanted[ref.Value] = true
+					add = append(add, ref.Value)
+				}
+				for userID := range members {
+					if !wanted[userID] {
+						remove = append(remove, userID)
+					}
+				}
+			default:
+				writeSCIMError(w, http.StatusBadRequest, "invalidValue", "Unsupported operation "+operation.Op)
+				return
+			}
+		default:
+			writeSCIMError(w, http.StatusBadRequest, "invalidPath", "Unsupported path "+path)
+			return
+		}
+	}
+
+	updated, err := h.service.UpdateTeamMembers(r.Context(), teamName, add, remove, model.ReviewsPolicyReassign)
+	if err != nil {
+		writeSCIMServiceError(w, r, err)
+		return
+	}
+
+	writeSCIM(w, http.StatusOK, toSCIMGroup(updated))
+}
+
+// scimDeleteGroup выводит всех участников из команды (с переназначением ревью) и удаляет её
+func (h *Handler) scimDeleteGroup(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DisbandTeam(r.Context(), chi.URLParam(r, "id")); err != nil {
+		writeSCIMServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func toSCIMUser(user *model.User) scimUser {
+	active := user.IsActive
+	result := scimUser{
+		Schemas:     []string{scimUserSchema},
+		ID:          user.UserID,
+		UserName:    user.UserID,
+		DisplayName: user.Username,
+		Name:        &scimName{Formatted: user.Username},
+		Active:      &active,
+		Meta:        &scimMeta{ResourceType: "User", Location: "/scim/v2/Users/" + user.UserID},
+	}
+	for _, team := range user.Teams {
+		result.Groups = append(result.Groups, scimRef{Value: team, Display: team})
+	}
+	return result
+}
+
+func toSCIMGroup(team *model.Team) scimGroup {
+	result := scimGroup{
+		Schemas:     []string{scimGroupSchema},
+		ID:          team.TeamName,
+		DisplayName: team.TeamName,
+		Members:     []scimRef{},
+		Meta:        &scimMeta{ResourceType: "Group", Location: "/scim/v2/Groups/" + team.TeamName},
+	}
+	for _, member := range team.Members {
+		result.Members = append(result.Members, scimRef{Value: member.UserID, Display: member.Username})
+	}
+	return result
+}
+
+// scimDisplayName выбирает имя пользователя из displayName, name или userName
+func scimDisplayName(user *scimUser) string {
+	if user.DisplayName != "" {
+		return user.DisplayName
+	}
+	if user.Name != nil {
+		if user.Name.Formatted != "" {
+			return user.Name.Formatted
+		}
+		if full := strings.TrimSpace(user.Name.GivenName + " " + user.Name.FamilyName); full != "" {
+			return full
+		}
+	}
+	return user.UserName
+}
+
+// parseSCIMFilter разбирает фильтр вида `attr eq "value"`; имя атрибута возвращается в нижнем регистре
+func parseSCIMFilter(expression string) (string, string, bool) {
+	match := scimFilterPattern.FindStringSubmatch(expression)
+	if match == nil {
+		return "", "", false
+	}
+	value := match[2]
+	if match[3] != "" {
+		value = strings.ToLower(match[3])
+	}
+	return strings.ToLower(match[1]), strings.ReplaceAll(value, `\"`, `"`), true
+}
+
+// parseSCIMBool принимает и JSON-булево, и строку: некоторые IdP присылают "False"
+func parseSCIMBool(value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return false, err
+	}
+	return strconv.ParseBool(s)
+}
+
+// scimPage читает startIndex (с 1) и count; при ошибке сам отвечает 400
+func scimPage(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	startIndex, count := 1, 0
+	if value := r.URL.Query().Get("startIndex"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			writeSCIMError(w, http.StatusBadRequest, "invalidValue", "Invalid startIndex")
+			return 0, 0, false
+		}
+		if parsed > 1 {
+			startIndex = parsed
+		}
+	}
+	if value := r.URL.Query().Get("count"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			writeSCIMError(w, http.StatusBadRequest, "invalidValue", "Invalid count")
+			return 0, 0, false
+		}
+		if parsed > 0 {
+			count = parsed
+		}
+	}
+	return startIndex, count, true
+}
+
+func writeSCIMUserList(w http.ResponseWriter, users []*model.User, total, startIndex int) {
+	resources := make([]scimUser, 0, len(users))
+	for _, user := range users {
+		resources = append(resources, toSCIMUser(user))
+	}
+
+	writeSCIM(w, http.StatusOK, scimListResponse{
+		Schemas:      []string{scimListSchema},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+func writeSCIM(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", scimContentType)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func writeSCIMError(w http.ResponseWriter, status int, scimType, detail string) {
+	writeSCIM(w, status, map[string]interface{}{
+		"schemas":  []string{scimErrorSchema},
+		"status":   strconv.Itoa(status),
+		"scimType": scimType,
+		"detail":   detail,
+	})
+}
+
+func writeSCIMServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	businessErr, ok := err.(service.BusinessError)
+	if !ok {
+		logInternalError(r, err)
+		writeSCIMError(w, http.StatusInternalServerError, "", "Internal server error")
+		return
+	}
+
+	switch businessErr.Code {
+	case "NOT_FOUND":
+		writeSCIMError(w, http.StatusNotFound, "", businessErr.Message)
+	case "USER_EXISTS", "TEAM_EXISTS":
+		writeSCIMError(w, http.StatusConflict, "uniqueness", businessErr.Message)
+	case "INVALID_INPUT":
+		writeSCIMError(w, http.StatusBadRequest, "invalidValue", businessErr.Message)
+	default:
+		writeSCIMError(w, http.StatusConflict, "", businessErr.Message)
+	}
+}
+
+func isNotFound(err error) bool {
+	businessErr, ok := err.(service.BusinessError)
+	return ok && businessErr.Code == "NOT_FOUND"
+}
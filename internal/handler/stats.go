@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"net/http"
+	"review-service/internal/model"
+	"time"
+)
+
+func (h *Handler) getReviewerStats(w http.ResponseWriter, r *http.Request) {
+	filter, ok := statsFilter(w, r)
+	if !ok {
+		return
+	}
+
+	stats, err := h.service.GetReviewerStats(r.Context(), filter)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"reviewers": stats})
+}
+
+func (h *Handler) getTeamStats(w http.ResponseWriter, r *http.Request) {
+	filter, ok := statsFilter(w, r)
+	if !ok {
+		return
+	}
+
+	stats, err := h.service.GetTeamStats(r.Context(), filter)
+	if err != nil {
+		handleServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"teams": stats})
+}
+
+// statsFilter читает from, to (RFC 3339 или YYYY-MM-DD) и team_name из query
+func statsFilter(w http.ResponseWriter, r *http.Request) (model.StatsFilter, bool) {
+	filter := model.StatsFilter{TeamName: r.URL.Query().Get("team_name")}
+	for name, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		value := r.URL.Query().Get(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := parseTimeParam(value)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, model.NewErrorResponse("INVALID_INPUT", "Invalid "+name+" parameter"))
+			return model.StatsFilter{}, false
+		}
+		*target = &parsed
+	}
+	return filter, true
+}
+
+// parseTimeParam разбирает момент времени из query: RFC 3339 или YYYY-MM-DD (начало дня UTC)
+func parseTimeParam(value string) (time.Time, error) {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		parsed, err = time.Parse(time.DateOnly, value)
+	}
+	return parsed, err
+}
@@ -0,0 +1,99 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"review-service/internal/model"
+	"strings"
+)
+
+const Provider = "github"
+
+const (
+	HeaderSignature = "X-Hub-Signature-256"
+	HeaderEvent     = "X-GitHub-Event"
+	HeaderDelivery  = "X-GitHub-Delivery"
+)
+
+type pullRequestPayload struct {
+	Action      string `json:"action"`
+	PullRequest struct {
+		Number int    `json:"number"`
+		Title  string `json:"title"`
+		Draft  bool   `json:"draft"`
+		Merged bool   `json:"merged"`
+		User   struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	} `json:"pull_request"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// VerifySignature проверяет заголовок X-Hub-Signature-256 (HMAC-SHA256 тела запроса)
+func VerifySignature(secret string, body []byte, signature string) bool {
+	if secret == "" || !strings.HasPrefix(signature, "sha256=") {
+		return false
+	}
+
+	got, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// PullRequestID строит идентификатор PR в сервисе по репозиторию и номеру на GitHub
+func PullRequestID(repository string, number int) string {
+	return fmt.Sprintf("%s:%s#%d", Provider, repository, number)
+}
+
+// ParsePullRequestEvent разбирает payload события pull_request.
+// Для действий, которые сервис не отслеживает, возвращает nil без ошибки.
+func ParsePullRequestEvent(body []byte) (*model.PullRequestEvent, error) {
+	var payload pullRequestPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+
+	if payload.Repository.FullName == "" || payload.PullRequest.Number == 0 {
+		return nil, fmt.Errorf("payload has no repository or pull request number")
+	}
+
+	var action string
+	switch payload.Action {
+	case "opened":
+		if payload.PullRequest.Draft {
+			return nil, nil
+		}
+		action = model.PREventOpen
+	case "ready_for_review":
+		action = model.PREventOpen
+	case "closed":
+		action = model.PREventClose
+		if payload.PullRequest.Merged {
+			action = model.PREventMerge
+		}
+	case "reopened":
+		action = model.PREventReopen
+	default:
+		return nil, nil
+	}
+
+	return &model.PullRequestEvent{
+		Provider:        Provider,
+		Action:          action,
+		PullRequestID:   PullRequestID(payload.Repository.FullName, payload.PullRequest.Number),
+		PullRequestName: payload.PullRequest.Title,
+		AuthorLogin:     payload.PullRequest.User.Login,
+		Repository:      payload.Repository.FullName,
+		Number:          payload.PullRequest.Number,
+	}, nil
+}
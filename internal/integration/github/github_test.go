@@ -0,0 +1,113 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"review-service/internal/model"
+	"testing"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestParsePullRequestEvent(t *testing.T) {
+	tests := []struct {
+		fixture string
+		action  string // пусто — событие игнорируется
+	}{
+		{"opened.json", model.PREventOpen},
+		{"opened_draft.json", ""},
+		{"ready_for_review.json", model.PREventOpen},
+		{"closed.json", model.PREventClose},
+		{"merged.json", model.PREventMerge},
+		{"reopened.json", model.PREventReopen},
+		{"synchronize.json", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			event, err := ParsePullRequestEvent(readFixture(t, tt.fixture))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.action == "" {
+				if event != nil {
+					t.Fatalf("expected event to be ignored, got %+v", event)
+				}
+				return
+			}
+			if event == nil {
+				t.Fatal("expected event, got nil")
+			}
+
+			want := model.PullRequestEvent{
+				Provider:        Provider,
+				Action:          tt.action,
+				PullRequestID:   "github:acme/payments#42",
+				PullRequestName: "Add idempotency keys to refunds",
+				AuthorLogin:     "octo-alice",
+				Repository:      "acme/payments",
+				Number:          42,
+			}
+			if *event != want {
+				t.Errorf("got %+v, want %+v", *event, want)
+			}
+		})
+	}
+}
+
+func TestParsePullRequestEventInvalid(t *testing.T) {
+	for name, body := range map[string]string{
+		"not json":     `{"action":`,
+		"no repo":      `{"action":"opened","pull_request":{"number":1}}`,
+		"no PR number": `{"action":"opened","repository":{"full_name":"acme/payments"}}`,
+	} {
+		if _, err := ParsePullRequestEvent([]byte(body)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	const secret = "webhook-secret"
+	body := readFixture(t, "opened.json")
+	tampered := append([]byte{}, body...)
+	tampered[len(tampered)-2] = ' '
+
+	tests := []struct {
+		name      string
+		secret    string
+		body      []byte
+		signature string
+		want      bool
+	}{
+		{"valid", secret, body, sign(secret, body), true},
+		{"wrong secret", secret, body, sign("other-secret", body), false},
+		{"tampered body", secret, tampered, sign(secret, body), false},
+		{"missing prefix", secret, body, sign(secret, body)[len("sha256="):], false},
+		{"not hex", secret, body, "sha256=zz", false},
+		{"empty", secret, body, "", false},
+		{"secret not configured", "", body, sign("", body), false},
+	}
+
+	for _, tt := range tests {
+		if got := VerifySignature(tt.secret, tt.body, tt.signature); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package gitlab
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"review-service/internal/model"
+	"strconv"
+)
+
+const Provider = "gitlab"
+
+const (
+	HeaderToken    = "X-Gitlab-Token"
+	HeaderEvent    = "X-Gitlab-Event"
+	HeaderDelivery = "X-Gitlab-Event-UUID"
+
+	EventMergeRequest = "Merge Request Hook"
+)
+
+type mergeRequestPayload struct {
+	ObjectKind string `json:"object_kind"`
+	User       struct {
+		ID       int    `json:"id"`
+		Username string `json:"username"`
+	} `json:"user"`
+	Project struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"project"`
+	ObjectAttributes struct {
+		IID      int    `json:"iid"`
+		Title    string `json:"title"`
+		Action   string `json:"action"`
+		Draft    bool   `json:"draft"`
+		AuthorID int    `json:"author_id"`
+	} `json:"object_attributes"`
+	Changes struct {
+		Draft *struct {
+			Previous bool `json:"previous"`
+			Current  bool `json:"current"`
+		} `json:"draft"`
+	} `json:"changes"`
+}
+
+// VerifyToken сравнивает X-Gitlab-Token с настроенным секретом
+func VerifyToken(secret, token string) bool {
+	if secret == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(secret), []byte(token)) == 1
+}
+
+// PullRequestID строит идентификатор PR в сервисе по проекту и iid merge request'а
+func PullRequestID(project string, iid int) string {
+	return fmt.Sprintf("%s:%s!%d", Provider, project, iid)
+}
+
+// ParseMergeRequestEvent разбирает payload Merge Request Hook.
+// GitLab передаёт только числовой id автора (author_id), а user — это тот, кто вызвал событие.
+// Логин автора заполняется, только если событие вызвал сам автор.
+// Для действий, которые сервис не отслеживает, возвращает nil без ошибки.
+func ParseMergeRequestEvent(body []byte) (*model.PullRequestEvent, error) {
+	var payload mergeRequestPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+
+	if payload.ObjectKind != "merge_request" {
+		return nil, fmt.Errorf("unexpected object_kind %q", payload.ObjectKind)
+	}
+	if payload.Project.PathWithNamespace == "" || payload.ObjectAttributes.IID == 0 {
+		return nil, fmt.Errorf("payload has no project or merge request iid")
+	}
+
+	var action string
+	switch payload.ObjectAttributes.Action {
+	case "open":
+		if payload.ObjectAttributes.Draft {
+			return nil, nil
+		}
+		action = model.PREventOpen
+	case "update":
+		// Снятие draft-статуса — аналог ready_for_review на GitHub
+		draft := payload.Changes.Draft
+		if draft == nil || !draft.Previous || draft.Current {
+			return nil, nil
+		}
+		action = model.PREventOpen
+	case "merge":
+		action = model.PREventMerge
+	case "close":
+		action = model.PREventClose
+	case "reopen":
+		action = model.PREventReopen
+	default:
+		return nil, nil
+	}
+
+	event := &model.PullRequestEvent{
+		Provider:        Provider,
+		Action:          action,
+		PullRequestID:   PullRequestID(payload.Project.PathWithNamespace, payload.ObjectAttributes.IID),
+		PullRequestName: payload.ObjectAttributes.Title,
+		Repository:      payload.Project.PathWithNamespace,
+		Number:          payload.ObjectAttributes.IID,
+	}
+	if authorID := payload.ObjectAttributes.AuthorID; authorID != 0 {
+		event.AuthorExternalID = strconv.Itoa(authorID)
+		if payload.User.ID == authorID {
+			event.AuthorLogin = payload.User.Username
+		}
+	}
+
+	return event, nil
+}
@@ -0,0 +1,95 @@
+package gitlab
+
+import (
+	"os"
+	"path/filepath"
+	"review-service/internal/model"
+	"testing"
+)
+
+func TestParseMergeRequestEvent(t *testing.T) {
+	tests := []struct {
+		fixture     string
+		action      string // пусто — событие игнорируется
+		authorLogin string
+	}{
+		{"open.json", model.PREventOpen, "gl-carol"},
+		{"open_draft.json", "", ""},
+		// Снять draft может не автор: логин вызвавшего не должен стать логином автора
+		{"ready_by_other.json", model.PREventOpen, ""},
+		{"update_title.json", "", ""},
+		{"merge.json", model.PREventMerge, ""},
+		{"close.json", model.PREventClose, "gl-carol"},
+		{"reopen_by_other.json", model.PREventReopen, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			body, err := os.ReadFile(filepath.Join("testdata", tt.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			event, err := ParseMergeRequestEvent(body)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.action == "" {
+				if event != nil {
+					t.Fatalf("expected event to be ignored, got %+v", event)
+				}
+				return
+			}
+			if event == nil {
+				t.Fatal("expected event, got nil")
+			}
+
+			want := model.PullRequestEvent{
+				Provider:         Provider,
+				Action:           tt.action,
+				PullRequestID:    "gitlab:platform/billing!17",
+				PullRequestName:  "Switch invoice numbering to sequences",
+				AuthorLogin:      tt.authorLogin,
+				AuthorExternalID: "301",
+				Repository:       "platform/billing",
+				Number:           17,
+			}
+			if *event != want {
+				t.Errorf("got %+v, want %+v", *event, want)
+			}
+		})
+	}
+}
+
+func TestParseMergeRequestEventInvalid(t *testing.T) {
+	for name, body := range map[string]string{
+		"not json":   `{"object_kind":`,
+		"wrong kind": `{"object_kind":"push","project":{"path_with_namespace":"a/b"},"object_attributes":{"iid":1}}`,
+		"no project": `{"object_kind":"merge_request","object_attributes":{"iid":1,"action":"open"}}`,
+		"no MR iid":  `{"object_kind":"merge_request","project":{"path_with_namespace":"a/b"}}`,
+	} {
+		if _, err := ParseMergeRequestEvent([]byte(body)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestVerifyToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret string
+		token  string
+		want   bool
+	}{
+		{"valid", "s3cret", "s3cret", true},
+		{"wrong", "s3cret", "guess", false},
+		{"empty token", "s3cret", "", false},
+		{"secret not configured", "", "", false},
+	}
+
+	for _, tt := range tests {
+		if got := VerifyToken(tt.secret, tt.token); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+// Package metrics собирает метрики Prometheus: HTTP-запросы по маршрутам chi,
+// бизнес-счётчики сервиса, открытые ревью по командам и статистику пула соединений.
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "review_service"
+
+// Причины переназначения ревьюера (метка reason)
+const (
+	ReasonManual     = "manual"
+	ReasonTeamChange = "team_change"
+)
+
+var registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route pattern, method and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route pattern and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	PullRequestsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "pull_requests_created_total",
+		Help:      "Pull requests created.",
+	})
+
+	PullRequestsMerged = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "pull_requests_merged_total",
+		Help:      "Open pull requests merged.",
+	})
+
+	Reassignments = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reviewer_reassignments_total",
+		Help:      "Reviewer reassignments by reason.",
+	}, []string{"reason"})
+
+	NoCandidate = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "no_candidate_total",
+		Help:      "Reassignments rejected with NO_CANDIDATE.",
+	})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests, httpDuration,
+		PullRequestsCreated, PullRequestsMerged, Reassignments, NoCandidate,
+	)
+}
+
+// Handler отдаёт метрики в формате Prometheus
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
+
+// RegisterPool добавляет статистику пула соединений с базой
+func RegisterPool(pool *pgxpool.Pool) {
+	registry.MustRegister(&poolCollector{pool: pool})
+}
+
+// OpenReviewsSource считает открытые ревью участников каждой команды
+type OpenReviewsSource interface {
+	CountOpenReviewsByTeam(ctx context.Context) (map[string]int, error)
+}
+
+// RegisterOpenReviews добавляет gauge открытых ревью по командам; значения читаются из базы при каждом сборе
+func RegisterOpenReviews(source OpenReviewsSource) {
+	registry.MustRegister(&openReviewsCollector{source: source})
+}
+
+var openReviewsDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "open_reviews"),
+	"Open pull request reviews assigned to team members (shadow reviews excluded).",
+	[]string{"team"}, nil,
+)
+
+type openReviewsCollector struct {
+	source OpenReviewsSource
+}
+
+func (c *openReviewsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- openReviewsDesc
+}
+
+func (c *openReviewsCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	counts, err := c.source.CountOpenReviewsByTeam(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to count open reviews for metrics", "error", err)
+		ch <- prometheus.NewInvalidMetric(openReviewsDesc, err)
+		return
+	}
+	for team, count := range counts {
+		ch <- prometheus.MustNewConstMetric(openReviewsDesc, prometheus.GaugeValue, float64(count), team)
+	}
+}
+
+var (
+	poolAcquiredDesc        = poolDesc("acquired_conns", "Connections currently acquired from the pool.")
+	poolIdleDesc            = poolDesc("idle_conns", "Idle connections in the pool.")
+	poolTotalDesc           = poolDesc("total_conns", "Total connections in the pool.")
+	poolMaxDesc             = poolDesc("max_conns", "Maximum size of the pool.")
+	poolAcquireCountDesc    = poolDesc("acquire_total", "Successful connection acquires.")
+	poolAcquireDurationDesc = poolDesc("acquire_duration_seconds_total", "Total time spent acquiring connections.")
+	poolEmptyAcquireDesc    = poolDesc("empty_acquire_total", "Acquires that had to wait because the pool was empty.")
+	poolCanceledAcquireDesc = poolDesc("canceled_acquire_total", "Acquires canceled by context.")
+	poolNewConnsDesc        = poolDesc("new_conns_total", "New connections opened.")
+)
+
+func poolDesc(name, help string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+}
+
+type poolCollector struct {
+	pool *pgxpool.Pool
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+	ch <- prometheus.MustNewConstMetric(poolAcquiredDesc, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(poolIdleDesc, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(poolTotalDesc, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(poolMaxDesc, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(poolAcquireCountDesc, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolAcquireDurationDesc, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(poolEmptyAcquireDesc, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolCanceledAcquireDesc, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolNewConnsDesc, prometheus.CounterValue, float64(stat.NewConnsCount()))
+}
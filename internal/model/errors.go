@@ -8,12 +8,21 @@ type ErrorResponse struct {
 }
 
 const (
-	ErrorTeamExists    = "TEAM_EXISTS"
-	ErrorPRExists      = "PR_EXISTS"
-	ErrorPRMerged      = "PR_MERGED"
-	ErrorNotAssigned   = "NOT_ASSIGNED"
-	ErrorNoCandidate   = "NO_CANDIDATE"
-	ErrorNotFound      = "NOT_FOUND"
+	ErrorTeamExists      = "TEAM_EXISTS"
+	ErrorPRExists        = "PR_EXISTS"
+	ErrorPRMerged        = "PR_MERGED"
+	ErrorPRClosed        = "PR_CLOSED"
+	ErrorNotAssigned     = "NOT_ASSIGNED"
+	ErrorNoCandidate     = "NO_CANDIDATE"
+	ErrorNotFound        = "NOT_FOUND"
+	ErrorUserExists      = "USER_EXISTS"
+	ErrorHasOpenReviews  = "HAS_OPEN_REVIEWS"
+	ErrorUserInOtherTeam = "USER_IN_OTHER_TEAM"
+	ErrorTeamCycle       = "TEAM_CYCLE"
+	ErrorTeamHasSubTeams = "TEAM_HAS_SUBTEAMS"
+	ErrorTeamHasOpenPRs  = "TEAM_HAS_OPEN_PRS"
+	ErrorUnauthorized    = "UNAUTHORIZED"
+	ErrorForbidden       = "FORBIDDEN"
 )
 
 func NewErrorResponse(code, message string) ErrorResponse {
@@ -21,4 +30,4 @@ func NewErrorResponse(code, message string) ErrorResponse {
 	resp.Error.Code = code
 	resp.Error.Message = message
 	return resp
-}
\ No newline at end of file
+}
@@ -1,38 +1,540 @@
 package model
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
+// User.TeamName — основная команда пользователя, Teams — все его команды (основная первой)
+// User.AbsentFrom и AbsentUntil — период отсутствия, в который пользователь не выбирается ревьюером
 type User struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	TeamName string `json:"team_name"`
-	IsActive bool   `json:"is_active"`
+	UserID      string     `json:"user_id"`
+	Username    string     `json:"username"`
+	TeamName    string     `json:"team_name"`
+	Teams       []string   `json:"teams,omitempty"`
+	IsActive    bool       `json:"is_active"`
+	AbsentFrom  *time.Time `json:"absent_from,omitempty"`
+	AbsentUntil *time.Time `json:"absent_until,omitempty"`
 }
 
+// UserFilter — фильтры и страница для списка пользователей; пустые поля не ограничивают выборку
+type UserFilter struct {
+	TeamName   string
+	IsActive   *bool
+	NamePrefix string
+	Limit      int
+	Offset     int
+}
+
+type UserList struct {
+	Users  []*User `json:"users"`
+	Total  int     `json:"total"`
+	Limit  int     `json:"limit"`
+	Offset int     `json:"offset"`
+}
+
+// UserUpdate — изменение профиля; nil-поля остаются без изменений
+type UserUpdate struct {
+	UserID   string  `json:"user_id"`
+	Username *string `json:"username,omitempty"`
+	IsActive *bool   `json:"is_active,omitempty"`
+}
+
+// Team.RequiredReviewerRoles — роли, представитель каждой из которых назначается ревьюером на PR команды.
+// При Mentorship на PR команды дополнительно назначается теневой ревьюер-стажёр в паре с senior.
 type Team struct {
-	TeamName string       `json:"team_name"`
-	Members  []TeamMember `json:"members"`
+	TeamName              string       `json:"team_name"`
+	ParentTeam            string       `json:"parent_team,omitempty"`
+	Escalation            string       `json:"escalation,omitempty"`
+	RequiredReviewerRoles []string     `json:"required_reviewer_roles,omitempty"`
+	Mentorship            bool         `json:"mentorship,omitempty"`
+	Members               []TeamMember `json:"members"`
 }
 
+// Escalation определяет, откуда добирать ревьюеров, если в команде автора не хватает кандидатов
+const (
+	EscalationNone               = "none"
+	EscalationSiblings           = "siblings"
+	EscalationParent             = "parent"
+	EscalationSiblingsThenParent = "siblings_then_parent"
+)
+
+type TeamTreeNode struct {
+	TeamName   string          `json:"team_name"`
+	Escalation string          `json:"escalation"`
+	SubTeams   []*TeamTreeNode `json:"sub_teams"`
+}
+
+// Ключи сортировки списка команд
+const (
+	TeamSortName          = "team_name"
+	TeamSortMembers       = "members"
+	TeamSortActiveMembers = "active_members"
+	TeamSortOpenReviews   = "open_reviews"
+)
+
+type TeamListFilter struct {
+	SortBy     string
+	Descending bool
+	Limit      int
+	Offset     int
+}
+
+// TeamSummary — сводка по команде; OpenReviews — число назначений участников команды на открытые PR
+type TeamSummary struct {
+	TeamName      string `json:"team_name"`
+	ParentTeam    string `json:"parent_team,omitempty"`
+	Members       int    `json:"members"`
+	ActiveMembers int    `json:"active_members"`
+	OpenReviews   int    `json:"open_reviews"`
+}
+
+type TeamList struct {
+	Teams  []*TeamSummary `json:"teams"`
+	Total  int            `json:"total"`
+	Limit  int            `json:"limit"`
+	Offset int            `json:"offset"`
+}
+
+// Роли участника в команде
+const (
+	RoleLead    = "lead"
+	RoleSenior  = "senior"
+	RoleMember  = "member"
+	RoleTrainee = "trainee"
+)
+
 type TeamMember struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	IsActive bool   `json:"is_active"`
+	UserID      string     `json:"user_id"`
+	Username    string     `json:"username"`
+	IsActive    bool       `json:"is_active"`
+	IsPrimary   bool       `json:"is_primary"`
+	Role        string     `json:"role"`
+	AbsentFrom  *time.Time `json:"absent_from,omitempty"`
+	AbsentUntil *time.Time `json:"absent_until,omitempty"`
+}
+
+// Available сообщает, можно ли назначить участника ревьюером в момент now: он активен и не отсутствует
+func (m TeamMember) Available(now time.Time) bool {
+	return m.IsActive && !Absent(m.AbsentFrom, m.AbsentUntil, now)
 }
 
+// Absent сообщает, попадает ли now в период отсутствия [from, until); пустой from — с любого момента
+func Absent(from, until *time.Time, now time.Time) bool {
+	if until == nil || !now.Before(*until) {
+		return false
+	}
+	return from == nil || !now.Before(*from)
+}
+
+// PullRequest.ShadowReviewers — стажёры, наблюдающие за ревью; в AssignedReviewers и лимит ревьюеров не входят.
+// Verdicts — вердикты ревьюеров (основных и теневых) по user_id, QuorumReached — см. HasQuorum.
 type PullRequest struct {
 	PullRequestID    string     `json:"pull_request_id"`
 	PullRequestName  string     `json:"pull_request_name"`
 	AuthorID         string     `json:"author_id"`
 	Status           string     `json:"status"`
 	AssignedReviewers []string  `json:"assigned_reviewers"`
+	ShadowReviewers  []string   `json:"shadow_reviewers,omitempty"`
+	Verdicts         map[string]string `json:"verdicts,omitempty"`
+	QuorumReached    bool       `json:"quorum_reached"`
 	CreatedAt        *time.Time `json:"createdAt,omitempty"`
 	MergedAt         *time.Time `json:"mergedAt,omitempty"`
 }
 
+// Вердикты ревьюеров
+const (
+	VerdictApproved         = "approved"
+	VerdictChangesRequested = "changes_requested"
+)
+
+// HasQuorum сообщает, что PR одобрен всеми основными ревьюерами. Теневые ревьюеры в кворум не входят:
+// их вердикты видны, но не нужны и ничего не блокируют. PR без основных ревьюеров кворума не имеет.
+func (pr *PullRequest) HasQuorum() bool {
+	if len(pr.AssignedReviewers) == 0 {
+		return false
+	}
+	for _, reviewer := range pr.AssignedReviewers {
+		if pr.Verdicts[reviewer] != VerdictApproved {
+			return false
+		}
+	}
+	return true
+}
+
 type PullRequestShort struct {
 	PullRequestID   string `json:"pull_request_id"`
 	PullRequestName string `json:"pull_request_name"`
 	AuthorID        string `json:"author_id"`
 	Status          string `json:"status"`
-}
\ No newline at end of file
+}
+
+const (
+	PREventOpen   = "open"
+	PREventMerge  = "merge"
+	PREventClose  = "close"
+	PREventReopen = "reopen"
+)
+
+// PullRequestEvent.AuthorLogin может быть пуст, если code host сообщил только числовой id автора
+type PullRequestEvent struct {
+	Provider         string
+	DeliveryID       string
+	Action           string
+	PullRequestID    string
+	PullRequestName  string
+	AuthorLogin      string
+	AuthorExternalID string
+	Repository       string
+	Number           int
+}
+
+type WebhookResult struct {
+	DeliveryID  string       `json:"delivery_id"`
+	Status      string       `json:"status"`
+	PullRequest *PullRequest `json:"pr,omitempty"`
+}
+
+// UserIdentity.ExternalID — числовой id аккаунта на code host'е; необязателен, узнаётся из вебхуков
+type UserIdentity struct {
+	Provider      string `json:"provider"`
+	ExternalLogin string `json:"login"`
+	ExternalID    string `json:"external_id,omitempty"`
+	UserID        string `json:"user_id"`
+}
+
+type PullRequestLink struct {
+	PullRequestID string
+	Provider      string
+	Repository    string
+	Number        int
+}
+
+const (
+	ReviewerSyncRequest = "REQUEST"
+	ReviewerSyncRemove  = "REMOVE"
+)
+
+type ReviewerSyncJob struct {
+	ID            int64
+	PullRequestID string
+	Action        string
+	UserIDs       []string
+	Attempts      int
+}
+
+const (
+	NotificationAssigned   = "ASSIGNED"
+	NotificationReassigned = "REASSIGNED"
+	NotificationMerged     = "MERGED"
+)
+
+const (
+	NotificationChannelSlack   = "slack"
+	NotificationChannelWebhook = "webhook"
+	NotificationChannelEmail   = "email"
+)
+
+type NotificationEvent struct {
+	Type          string
+	PullRequest   *PullRequest
+	RecipientIDs  []string
+	OldReviewerID string
+	NewReviewerID string
+}
+
+type NotificationPreference struct {
+	UserID       string `json:"user_id"`
+	Channel      string `json:"channel"`
+	Target       string `json:"target"`
+	OnAssigned   bool   `json:"on_assigned"`
+	OnReassigned bool   `json:"on_reassigned"`
+	OnMerged     bool   `json:"on_merged"`
+	DailyDigest  bool   `json:"daily_digest"`
+}
+
+// NotificationPreferenceUpdate — запрос на подписку. nil-флаги сохраняют прежнее значение,
+// а для новой подписки принимают значения по умолчанию: все события — да, дайджест — нет.
+type NotificationPreferenceUpdate struct {
+	UserID       string `json:"user_id"`
+	Channel      string `json:"channel"`
+	Target       string `json:"target"`
+	OnAssigned   *bool  `json:"on_assigned,omitempty"`
+	OnReassigned *bool  `json:"on_reassigned,omitempty"`
+	OnMerged     *bool  `json:"on_merged,omitempty"`
+	DailyDigest  *bool  `json:"daily_digest,omitempty"`
+}
+
+// Wants сообщает, подписан ли пользователь на события данного типа
+func (p *NotificationPreference) Wants(eventType string) bool {
+	switch eventType {
+	case NotificationAssigned:
+		return p.OnAssigned
+	case NotificationReassigned:
+		return p.OnReassigned
+	case NotificationMerged:
+		return p.OnMerged
+	}
+	return false
+}
+
+const (
+	ReviewsPolicyKeep     = "keep"
+	ReviewsPolicyReassign = "reassign"
+	ReviewsPolicyReject   = "reject"
+)
+
+// ReviewerReplacement описывает замену ревьюера в PR; пустой NewUserID означает снятие без замены
+type ReviewerReplacement struct {
+	PullRequestID string `json:"pull_request_id"`
+	OldUserID     string `json:"old_user_id"`
+	NewUserID     string `json:"replaced_by,omitempty"`
+}
+
+type MembershipChange struct {
+	User          *User                 `json:"user"`
+	ReviewsPolicy string                `json:"reviews_policy"`
+	Replacements  []ReviewerReplacement `json:"replacements"`
+}
+
+const (
+	ConflictPolicyReject = "reject"
+	ConflictPolicyMove   = "move"
+	ConflictPolicySkip   = "skip"
+	ConflictPolicyJoin   = "join"
+)
+
+const (
+	MemberCreated  = "created"
+	MemberMoved    = "moved"
+	MemberSkipped  = "skipped"
+	MemberJoined   = "joined"
+	MemberConflict = "conflict"
+)
+
+type MemberOutcome struct {
+	UserID       string `json:"user_id"`
+	Outcome      string `json:"outcome"`
+	PreviousTeam string `json:"previous_team,omitempty"`
+}
+
+type TeamCreation struct {
+	Team    *Team           `json:"team"`
+	Members []MemberOutcome `json:"members_report"`
+}
+
+// ImportTeam — команда из файла импорта. Незаданные поля (пустые строки, nil) оставляют
+// текущее значение существующей команды; Line — строка файла, где команда описана.
+type ImportTeam struct {
+	TeamName              string
+	ParentTeam            string
+	Escalation            string
+	RequiredReviewerRoles []string
+	Mentorship            *bool
+	Members               []ImportMember
+	Line                  int
+}
+
+// ImportMember — членство пользователя в команде из файла импорта.
+// IsPrimary в файле означает явное указание основной команды; после разбора плана — итоговое значение.
+type ImportMember struct {
+	UserID    string
+	Username  string
+	IsActive  *bool
+	Role      string
+	IsPrimary bool
+	Line      int
+}
+
+const (
+	ImportCreateTeam       = "create_team"
+	ImportUpdateTeam       = "update_team"
+	ImportCreateUser       = "create_user"
+	ImportUpdateUser       = "update_user"
+	ImportAddMembership    = "add_membership"
+	ImportUpdateMembership = "update_membership"
+)
+
+type ImportChange struct {
+	Line   int    `json:"line"`
+	Action string `json:"action"`
+	Target string `json:"target"`
+	Detail string `json:"detail,omitempty"`
+}
+
+type ImportError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+type ImportResult struct {
+	DryRun  bool           `json:"dry_run"`
+	Applied bool           `json:"applied"`
+	Changes []ImportChange `json:"changes"`
+	Errors  []ImportError  `json:"errors,omitempty"`
+}
+
+// StatsFilter — полуинтервал [From, To); nil-границы не ограничивают выборку
+type StatsFilter struct {
+	From     *time.Time
+	To       *time.Time
+	TeamName string
+}
+
+// ReviewerStats — назначения ревьюера за период (по времени назначения) и судьба этих PR.
+// AvgTimeToMergeHours считается по слитым PR от создания до слияния.
+type ReviewerStats struct {
+	UserID              string   `json:"user_id"`
+	Username            string   `json:"username"`
+	Assigned            int      `json:"assigned"`
+	Open                int      `json:"open"`
+	Merged              int      `json:"merged"`
+	ReassignedIn        int      `json:"reassigned_in"`
+	ReassignedOut       int      `json:"reassigned_out"`
+	AvgTimeToMergeHours *float64 `json:"avg_time_to_merge_hours"`
+}
+
+// TeamStats — PR, созданные за период авторами, для которых команда основная, и ревью её участников
+type TeamStats struct {
+	TeamName            string   `json:"team_name"`
+	PullRequests        int      `json:"pull_requests"`
+	Open                int      `json:"open"`
+	Merged              int      `json:"merged"`
+	Assignments         int      `json:"assignments"`
+	ReassignedIn        int      `json:"reassigned_in"`
+	ReassignedOut       int      `json:"reassigned_out"`
+	AvgTimeToMergeHours *float64 `json:"avg_time_to_merge_hours"`
+}
+
+// Области доступа API-токена. Каждая следующая включает предыдущие: write даёт и read, admin — всё.
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+	ScopeAdmin = "admin"
+)
+
+var scopeLevels = map[string]int{ScopeRead: 1, ScopeWrite: 2, ScopeAdmin: 3}
+
+// ValidScope сообщает, известна ли область доступа
+func ValidScope(scope string) bool {
+	_, ok := scopeLevels[scope]
+	return ok
+}
+
+// APIToken — выпущенный токен без секрета
+type APIToken struct {
+	TokenID    int64      `json:"token_id"`
+	UserID     string     `json:"user_id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  *time.Time `json:"created_at,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Способы аутентификации вызывающего
+const (
+	AuthMethodAPIToken = "api_token"
+	AuthMethodOIDC     = "oidc"
+)
+
+// Principal — аутентифицированный вызывающий: владелец API-токена или пользователь SSO
+type Principal struct {
+	UserID string
+	Scopes []string
+	Method string
+}
+
+// HasScope проверяет, покрывают ли области вызывающего запрошенную
+func (p *Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if scopeLevels[s] >= scopeLevels[scope] {
+			return true
+		}
+	}
+	return false
+}
+
+// IssuedToken — ответ на выпуск токена; Token возвращается только здесь
+type IssuedToken struct {
+	Token string    `json:"token"`
+	Info  *APIToken `json:"info"`
+}
+
+// Типы объектов в журнале аудита
+const (
+	AuditTargetTeam          = "team"
+	AuditTargetUser          = "user"
+	AuditTargetPullRequest   = "pull_request"
+	AuditTargetNotifications = "notification_preferences"
+	AuditTargetIdentities    = "user_identities"
+	AuditTargetAPIToken      = "api_token"
+)
+
+// AuditEntry — запись журнала аудита. Before и After — состояние объекта до и после изменения
+// (null, если объекта не было или не стало).
+type AuditEntry struct {
+	AuditID    int64           `json:"audit_id"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	ActorType  string          `json:"actor_type"`
+	ActorID    string          `json:"actor_id"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   string          `json:"target_id"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	RequestID  string          `json:"request_id,omitempty"`
+}
+
+// AuditFilter — пустые поля не ограничивают выборку; период — полуинтервал [From, To)
+type AuditFilter struct {
+	ActorID    string
+	Action     string
+	TargetType string
+	TargetID   string
+	RequestID  string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+}
+
+type AuditLog struct {
+	Entries []*AuditEntry `json:"entries"`
+	Total   int           `json:"total"`
+	Limit   int           `json:"limit"`
+	Offset  int           `json:"offset"`
+}
+
+// Типы событий истории PR
+const (
+	PRHistoryCreated    = "created"
+	PRHistoryAssigned   = "assigned"
+	PRHistoryUnassigned = "unassigned"
+	PRHistoryReassigned = "reassigned"
+	PRHistoryMerged     = "merged"
+	PRHistoryClosed     = "closed"
+	PRHistoryReopened   = "reopened"
+	PRHistoryVerdict    = "verdict"
+)
+
+// PullRequestHistoryEvent — событие истории PR. UserID — затронутый ревьюер (при переназначении — новый,
+// OldUserID — прежний), у created — автор. Reason задан у переназначений и снятий, Verdict — у вердиктов.
+type PullRequestHistoryEvent struct {
+	EventID    int64     `json:"event_id"`
+	Type       string    `json:"type"`
+	UserID     string    `json:"user_id,omitempty"`
+	OldUserID  string    `json:"old_user_id,omitempty"`
+	IsShadow   bool      `json:"is_shadow,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+	Verdict    string    `json:"verdict,omitempty"`
+	ActorType  string    `json:"actor_type"`
+	ActorID    string    `json:"actor_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+type PullRequestHistory struct {
+	PullRequestID string                     `json:"pull_request_id"`
+	Events        []*PullRequestHistoryEvent `json:"events"`
+}
@@ -0,0 +1,74 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPullRequestHasQuorum(t *testing.T) {
+	cases := []struct {
+		name     string
+		pr       PullRequest
+		expected bool
+	}{
+		{"no reviewers", PullRequest{}, false},
+		{"no verdicts", PullRequest{AssignedReviewers: []string{"a", "b"}}, false},
+		{"one of two approved", PullRequest{
+			AssignedReviewers: []string{"a", "b"},
+			Verdicts:          map[string]string{"a": VerdictApproved},
+		}, false},
+		{"all approved", PullRequest{
+			AssignedReviewers: []string{"a", "b"},
+			Verdicts:          map[string]string{"a": VerdictApproved, "b": VerdictApproved},
+		}, true},
+		{"changes requested", PullRequest{
+			AssignedReviewers: []string{"a", "b"},
+			Verdicts:          map[string]string{"a": VerdictApproved, "b": VerdictChangesRequested},
+		}, false},
+		{"shadow approval does not count", PullRequest{
+			AssignedReviewers: []string{"a"},
+			ShadowReviewers:   []string{"t"},
+			Verdicts:          map[string]string{"t": VerdictApproved},
+		}, false},
+		{"shadow changes request does not block", PullRequest{
+			AssignedReviewers: []string{"a"},
+			ShadowReviewers:   []string{"t"},
+			Verdicts:          map[string]string{"a": VerdictApproved, "t": VerdictChangesRequested},
+		}, true},
+	}
+
+	for _, tc := range cases {
+		if got := tc.pr.HasQuorum(); got != tc.expected {
+			t.Errorf("%s: HasQuorum() = %v, want %v", tc.name, got, tc.expected)
+		}
+	}
+}
+
+func TestAbsent(t *testing.T) {
+	now := time.Date(2026, 1, 12, 10, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		t := now.Add(d)
+		return &t
+	}
+	cases := []struct {
+		name        string
+		from, until *time.Time
+		expected    bool
+	}{
+		{"no absence", nil, nil, false},
+		{"current period", at(-time.Hour), at(time.Hour), true},
+		{"open start", nil, at(time.Hour), true},
+		{"future period", at(time.Hour), at(2 * time.Hour), false},
+		{"past period", at(-2 * time.Hour), at(-time.Hour), false},
+		{"ends now", at(-time.Hour), at(0), false},
+		{"starts now", at(0), at(time.Hour), true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Absent(tc.from, tc.until, now); got != tc.expected {
+				t.Fatalf("Absent() = %v, want %v", got, tc.expected)
+			}
+		})
+	}
+}
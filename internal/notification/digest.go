@@ -0,0 +1,83 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"review-service/internal/model"
+	"review-service/internal/repository"
+	"time"
+)
+
+type digestData struct {
+	UserID       string
+	PullRequests []*model.PullRequestShort
+}
+
+// DigestScheduler раз в сутки в заданный час рассылает подписчикам список их открытых ревью
+type DigestScheduler struct {
+	repo   repository.Repository
+	mailer *Mailer
+	hour   int
+}
+
+func NewDigestScheduler(repo repository.Repository, mailer *Mailer, hour int) *DigestScheduler {
+	return &DigestScheduler{repo: repo, mailer: mailer, hour: hour}
+}
+
+func (d *DigestScheduler) Run(ctx context.Context) {
+	for {
+		timer := time.NewTimer(time.Until(nextRun(time.Now(), d.hour)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if err := d.SendDigests(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "daily digest failed", "error", err)
+		}
+	}
+}
+
+// SendDigests отправляет дайджест всем подписчикам, у которых есть открытые ревью
+func (d *DigestScheduler) SendDigests(ctx context.Context) error {
+	subscribers, err := d.repo.GetDigestSubscribers(ctx, model.NotificationChannelEmail)
+	if err != nil {
+		return err
+	}
+
+	for _, sub := range subscribers {
+		prs, err := d.repo.GetUserReviewRequests(ctx, sub.UserID)
+		if err != nil {
+			return err
+		}
+
+		var open []*model.PullRequestShort
+		for _, pr := range prs {
+			if pr.Status == "OPEN" {
+				open = append(open, pr)
+			}
+		}
+		if len(open) == 0 {
+			continue
+		}
+
+		subject := fmt.Sprintf("You have %d open review(s)", len(open))
+		data := digestData{UserID: sub.UserID, PullRequests: open}
+		if err := d.mailer.SendTemplate(ctx, sub.Target, subject, "digest", data); err != nil {
+			slog.ErrorContext(ctx, "failed to send daily digest", "user_id", sub.UserID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+func nextRun(now time.Time, hour int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
@@ -0,0 +1,91 @@
+package notification
+
+import (
+	"context"
+	"log/slog"
+	"review-service/internal/model"
+	"review-service/internal/repository"
+	"sync"
+	"time"
+)
+
+const sendTimeout = 10 * time.Second
+
+// Channel доставляет одно сообщение получателю по адресу target из его настроек
+type Channel interface {
+	Send(ctx context.Context, target string, msg *Message) error
+}
+
+// Dispatcher складывает события в очередь и рассылает их в фоне,
+// чтобы HTTP-запросы не ждали внешних сервисов
+type Dispatcher struct {
+	repo     repository.NotificationRepository
+	channels map[string]Channel
+	queue    chan *model.NotificationEvent
+	wg       sync.WaitGroup
+}
+
+func NewDispatcher(repo repository.NotificationRepository, channels map[string]Channel, queueSize int) *Dispatcher {
+	return &Dispatcher{
+		repo:     repo,
+		channels: channels,
+		queue:    make(chan *model.NotificationEvent, queueSize),
+	}
+}
+
+// Notify не блокирует: при переполненной очереди событие отбрасывается
+func (d *Dispatcher) Notify(event *model.NotificationEvent) {
+	select {
+	case d.queue <- event:
+	default:
+		slog.Warn("notification queue is full, dropping event", "event", event.Type, "pull_request_id", event.PullRequest.PullRequestID)
+	}
+}
+
+// Start запускает workers обработчиков очереди; они завершаются при отмене ctx
+func (d *Dispatcher) Start(ctx context.Context, workers int) {
+	for i := 0; i < workers; i++ {
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case event := <-d.queue:
+					d.dispatch(ctx, event)
+				}
+			}
+		}()
+	}
+}
+
+func (d *Dispatcher) Wait() {
+	d.wg.Wait()
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context, event *model.NotificationEvent) {
+	prefs, err := d.repo.GetNotificationPreferences(ctx, event.RecipientIDs)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to load notification preferences", "pull_request_id", event.PullRequest.PullRequestID, "error", err)
+		return
+	}
+
+	for _, pref := range prefs {
+		if !pref.Wants(event.Type) {
+			continue
+		}
+
+		channel, ok := d.channels[pref.Channel]
+		if !ok {
+			continue
+		}
+
+		sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+		err := channel.Send(sendCtx, pref.Target, NewMessage(event, pref.UserID))
+		cancel()
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to send notification", "event", event.Type, "user_id", pref.UserID, "channel", pref.Channel, "error", err)
+		}
+	}
+}
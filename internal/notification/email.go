@@ -0,0 +1,177 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"review-service/internal/model"
+	"review-service/pkg/config"
+	"strconv"
+	texttemplate "text/template"
+	"time"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+var (
+	textTemplates = texttemplate.Must(texttemplate.ParseFS(templateFS, "templates/*.txt.tmpl"))
+	htmlTemplates = htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/*.html.tmpl"))
+)
+
+// Mailer отправляет письма с текстовой и HTML-версией через SMTP
+type Mailer struct {
+	cfg config.SMTPConfig
+}
+
+func NewMailer(cfg config.SMTPConfig) *Mailer {
+	return &Mailer{cfg: cfg}
+}
+
+// SendTemplate рендерит пару шаблонов <name>.txt.tmpl / <name>.html.tmpl и отправляет письмо
+func (m *Mailer) SendTemplate(ctx context.Context, to, subject, name string, data interface{}) error {
+	var text, html bytes.Buffer
+	if err := textTemplates.ExecuteTemplate(&text, name+".txt.tmpl", data); err != nil {
+		return err
+	}
+	if err := htmlTemplates.ExecuteTemplate(&html, name+".html.tmpl", data); err != nil {
+		return err
+	}
+
+	msg, err := m.buildMessage(to, subject, text.Bytes(), html.Bytes())
+	if err != nil {
+		return err
+	}
+	return m.send(ctx, to, msg)
+}
+
+func (m *Mailer) buildMessage(to, subject string, text, html []byte) ([]byte, error) {
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+
+	for _, part := range []struct {
+		contentType string
+		content     []byte
+	}{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", html},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write(part.content); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", m.cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", parts.Boundary())
+	msg.Write(body.Bytes())
+
+	return msg.Bytes(), nil
+}
+
+func (m *Mailer) send(ctx context.Context, to string, msg []byte) error {
+	addr := net.JoinHostPort(m.cfg.Host, strconv.Itoa(m.cfg.Port))
+	tlsConfig := &tls.Config{ServerName: m.cfg.Host}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if m.cfg.TLSMode == "tls" {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	client, err := smtp.NewClient(conn, m.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if m.cfg.TLSMode == "starttls" {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+
+	if m.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(m.cfg.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+type emailChannel struct {
+	mailer *Mailer
+}
+
+// NewEmailChannel отправляет уведомления письмом на адрес из настроек пользователя
+func NewEmailChannel(mailer *Mailer) Channel {
+	return &emailChannel{mailer: mailer}
+}
+
+func (c *emailChannel) Send(ctx context.Context, target string, msg *Message) error {
+	pr := msg.PullRequest
+
+	switch {
+	case msg.Type == model.NotificationAssigned:
+		return c.mailer.SendTemplate(ctx, target, "Review requested: "+pr.PullRequestName, "assigned", msg)
+	case msg.Type == model.NotificationReassigned && msg.RecipientID == msg.NewReviewerID:
+		return c.mailer.SendTemplate(ctx, target, "Review requested: "+pr.PullRequestName, "assigned", msg)
+	case msg.Type == model.NotificationReassigned:
+		return c.mailer.SendTemplate(ctx, target, "You were replaced as reviewer: "+pr.PullRequestName, "replaced", msg)
+	default:
+		return c.mailer.SendTemplate(ctx, target, "Pull request update: "+pr.PullRequestName, "notice", msg)
+	}
+}
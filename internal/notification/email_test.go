@@ -0,0 +1,223 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"review-service/internal/model"
+	"review-service/internal/repository"
+	"review-service/pkg/config"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// receivedMail — письмо, принятое тестовым SMTP-сервером
+type receivedMail struct {
+	from string
+	to   []string
+	data []byte
+}
+
+// startSMTPServer поднимает минимальный SMTP-сервер без TLS и авторизации и
+// складывает принятые письма в канал
+func startSMTPServer(t *testing.T) (config.SMTPConfig, <-chan receivedMail) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	received := make(chan receivedMail, 10)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveSMTP(conn, received)
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	portNum, _ := strconv.Atoi(port)
+	return config.SMTPConfig{Host: host, Port: portNum, From: "reviews@example.com"}, received
+}
+
+func serveSMTP(conn net.Conn, received chan<- receivedMail) {
+	tp := textproto.NewConn(conn)
+	defer tp.Close()
+
+	tp.PrintfLine("220 localhost ESMTP test")
+	var current receivedMail
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			tp.PrintfLine("250 localhost")
+		case "MAIL":
+			current = receivedMail{from: strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")}
+			tp.PrintfLine("250 OK")
+		case "RCPT":
+			current.to = append(current.to, strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>"))
+			tp.PrintfLine("250 OK")
+		case "DATA":
+			tp.PrintfLine("354 Go ahead")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			current.data = data
+			received <- current
+			tp.PrintfLine("250 OK")
+		case "QUIT":
+			tp.PrintfLine("221 Bye")
+			return
+		default:
+			tp.PrintfLine("502 Not implemented")
+		}
+	}
+}
+
+// parseMail возвращает тему и текстовую часть письма
+func parseMail(t *testing.T, data []byte) (string, string) {
+	t.Helper()
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("unexpected content type %q: %v", msg.Header.Get("Content-Type"), err)
+	}
+
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	var text string
+	var sawHTML bool
+	for {
+		part, err := parts.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(quotedprintable.NewReader(part))
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch {
+		case strings.HasPrefix(part.Header.Get("Content-Type"), "text/plain"):
+			text = string(body)
+		case strings.HasPrefix(part.Header.Get("Content-Type"), "text/html"):
+			sawHTML = true
+		}
+	}
+	if text == "" || !sawHTML {
+		t.Fatalf("expected text and html parts, got text=%q html=%v", text, sawHTML)
+	}
+	return subject, text
+}
+
+func TestEmailChannelSendsAssignment(t *testing.T) {
+	cfg, received := startSMTPServer(t)
+	channel := NewEmailChannel(NewMailer(cfg))
+
+	err := channel.Send(context.Background(), "alice@example.com", &Message{
+		Type:        model.NotificationAssigned,
+		RecipientID: "alice",
+		PullRequest: &model.PullRequest{PullRequestID: "pr-1", PullRequestName: "Add search", AuthorID: "bob"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := <-received
+	if got.from != "reviews@example.com" || len(got.to) != 1 || got.to[0] != "alice@example.com" {
+		t.Fatalf("unexpected envelope: from=%q to=%v", got.from, got.to)
+	}
+	subject, text := parseMail(t, got.data)
+	if subject != "Review requested: Add search" {
+		t.Errorf("subject = %q", subject)
+	}
+	if !strings.Contains(text, `You were assigned to review "Add search" (pr-1) by bob.`) {
+		t.Errorf("unexpected body:\n%s", text)
+	}
+}
+
+// digestRepo — репозиторий с подписчиками дайджеста и их ревью
+type digestRepo struct {
+	repository.Repository
+	subscribers []*model.NotificationPreference
+	reviews     map[string][]*model.PullRequestShort
+}
+
+func (r *digestRepo) GetDigestSubscribers(ctx context.Context, channel string) ([]*model.NotificationPreference, error) {
+	return r.subscribers, nil
+}
+
+func (r *digestRepo) GetUserReviewRequests(ctx context.Context, userID string) ([]*model.PullRequestShort, error) {
+	return r.reviews[userID], nil
+}
+
+func TestSendDigests(t *testing.T) {
+	cfg, received := startSMTPServer(t)
+	repo := &digestRepo{
+		subscribers: []*model.NotificationPreference{
+			{UserID: "alice", Channel: model.NotificationChannelEmail, Target: "alice@example.com", DailyDigest: true},
+			{UserID: "carol", Channel: model.NotificationChannelEmail, Target: "carol@example.com", DailyDigest: true},
+		},
+		reviews: map[string][]*model.PullRequestShort{
+			"alice": {
+				{PullRequestID: "pr-1", PullRequestName: "Add search", AuthorID: "bob", Status: "OPEN"},
+				{PullRequestID: "pr-2", PullRequestName: "Fix login", AuthorID: "bob", Status: "MERGED"},
+				{PullRequestID: "pr-3", PullRequestName: "Bump deps", AuthorID: "dave", Status: "OPEN"},
+			},
+			"carol": {
+				{PullRequestID: "pr-4", PullRequestName: "Old work", AuthorID: "bob", Status: "MERGED"},
+			},
+		},
+	}
+
+	if err := NewDigestScheduler(repo, NewMailer(cfg), 9).SendDigests(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	got := <-received
+	if len(got.to) != 1 || got.to[0] != "alice@example.com" {
+		t.Fatalf("digest sent to %v", got.to)
+	}
+	subject, text := parseMail(t, got.data)
+	if subject != "You have 2 open review(s)" {
+		t.Errorf("subject = %q", subject)
+	}
+	for _, want := range []string{`"Add search" (pr-1) by bob`, `"Bump deps" (pr-3) by dave`} {
+		if !strings.Contains(text, want) {
+			t.Errorf("digest does not mention %s:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "pr-2") {
+		t.Errorf("digest lists a merged pull request:\n%s", text)
+	}
+
+	select {
+	case extra := <-received:
+		t.Fatalf("unexpected digest to %v for a user without open reviews", extra.to)
+	default:
+	}
+}
@@ -0,0 +1,45 @@
+package notification
+
+import (
+	"fmt"
+	"review-service/internal/model"
+)
+
+type Message struct {
+	Type          string             `json:"type"`
+	RecipientID   string             `json:"recipient_id"`
+	Text          string             `json:"text"`
+	PullRequest   *model.PullRequest `json:"pull_request"`
+	OldReviewerID string             `json:"old_reviewer_id,omitempty"`
+	NewReviewerID string             `json:"new_reviewer_id,omitempty"`
+}
+
+func NewMessage(event *model.NotificationEvent, recipientID string) *Message {
+	return &Message{
+		Type:          event.Type,
+		RecipientID:   recipientID,
+		Text:          FormatText(event, recipientID),
+		PullRequest:   event.PullRequest,
+		OldReviewerID: event.OldReviewerID,
+		NewReviewerID: event.NewReviewerID,
+	}
+}
+
+// FormatText формирует текст уведомления с точки зрения получателя
+func FormatText(event *model.NotificationEvent, recipientID string) string {
+	pr := event.PullRequest
+	title := fmt.Sprintf("%q (%s) by %s", pr.PullRequestName, pr.PullRequestID, pr.AuthorID)
+
+	switch event.Type {
+	case model.NotificationAssigned:
+		return fmt.Sprintf("You were assigned to review %s", title)
+	case model.NotificationReassigned:
+		if recipientID == event.OldReviewerID {
+			return fmt.Sprintf("You were replaced by %s as reviewer of %s", event.NewReviewerID, title)
+		}
+		return fmt.Sprintf("You were assigned to review %s instead of %s", title, event.OldReviewerID)
+	case model.NotificationMerged:
+		return fmt.Sprintf("%s was merged", title)
+	}
+	return title
+}
@@ -0,0 +1,111 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// Адреса назначения задают сами пользователи, поэтому запросы во внутреннюю сеть запрещены:
+// проверяется IP, к которому идёт соединение, в том числе после DNS и при редиректах.
+// Прокси из окружения не используется, иначе проверялся бы адрес прокси.
+func newPublicHTTPClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !PublicIP(net.IP(addrPort.Addr().AsSlice())) {
+				return fmt.Errorf("refusing to connect to non-public address %s", addrPort.Addr())
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: &http.Transport{DialContext: dialer.DialContext, TLSHandshakeTimeout: 5 * time.Second},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if req.URL.Scheme != "https" {
+				return fmt.Errorf("refusing redirect to non-https URL")
+			}
+			if len(via) >= 5 {
+				return fmt.Errorf("stopped after %d redirects", len(via))
+			}
+			return nil
+		},
+	}
+}
+
+// sharedAddressSpace — 100.64.0.0/10 (CGNAT), не покрытый net.IP.IsPrivate
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// PublicIP сообщает, что адрес маршрутизируется в интернете: не loopback, не частная сеть,
+// не link-local (в том числе адрес метаданных облака 169.254.169.254) и не multicast
+func PublicIP(ip net.IP) bool {
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return false
+	}
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() && !addr.IsPrivate() && !sharedAddressSpace.Contains(addr)
+}
+
+type webhookChannel struct {
+	httpClient *http.Client
+	payload    func(msg *Message) interface{}
+}
+
+// NewSlackChannel отправляет сообщения в Slack-совместимый incoming webhook
+func NewSlackChannel() Channel {
+	return &webhookChannel{
+		httpClient: newPublicHTTPClient(),
+		payload: func(msg *Message) interface{} {
+			return map[string]string{"text": msg.Text}
+		},
+	}
+}
+
+// NewWebhookChannel отправляет сообщение целиком в виде JSON на произвольный URL
+func NewWebhookChannel() Channel {
+	return &webhookChannel{
+		httpClient: newPublicHTTPClient(),
+		payload: func(msg *Message) interface{} {
+			return msg
+		},
+	}
+}
+
+func (c *webhookChannel) Send(ctx context.Context, target string, msg *Message) error {
+	body, err := json.Marshal(c.payload(msg))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if req.URL.Scheme != "https" {
+		return fmt.Errorf("webhook target must use https")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package notification
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPublicIP(t *testing.T) {
+	cases := map[string]bool{
+		"8.8.8.8":          true,
+		"2606:4700::1111":  true,
+		"127.0.0.1":        false,
+		"::1":              false,
+		"10.1.2.3":         false,
+		"172.16.0.1":       false,
+		"192.168.1.1":      false,
+		"169.254.169.254":  false,
+		"100.64.0.1":       false,
+		"0.0.0.0":          false,
+		"224.0.0.1":        false,
+		"fc00::1":          false,
+		"fe80::1":          false,
+		"::ffff:127.0.0.1": false,
+	}
+	for addr, want := range cases {
+		if got := PublicIP(net.ParseIP(addr)); got != want {
+			t.Errorf("PublicIP(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func TestWebhookChannelRefusesInternalAddress(t *testing.T) {
+	called := false
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer srv.Close()
+
+	err := NewWebhookChannel().Send(context.Background(), srv.URL, &Message{Text: "hi"})
+	if err == nil || !strings.Contains(err.Error(), "non-public address") {
+		t.Fatalf("expected non-public address error, got %v", err)
+	}
+	if called {
+		t.Fatal("request reached the loopback server")
+	}
+}
+
+func TestWebhookChannelRequiresHTTPS(t *testing.T) {
+	err := NewSlackChannel().Send(context.Background(), "http://example.com/hook", &Message{Text: "hi"})
+	if err == nil || !strings.Contains(err.Error(), "https") {
+		t.Fatalf("expected https error, got %v", err)
+	}
+}
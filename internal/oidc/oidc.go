@@ -0,0 +1,300 @@
+// Package oidc проверяет JWT от провайдера SSO по его JWKS (из файла или по URL)
+// и содержит локальный stub-издатель для разработки и тестов.
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"review-service/internal/model"
+	"review-service/pkg/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// refreshInterval ограничивает перечитывание JWKS при встрече незнакомого kid
+const refreshInterval = time.Minute
+
+var validMethods = []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}
+
+// ErrInvalidToken — подпись, срок действия, издатель или аудитория не прошли проверку
+var ErrInvalidToken = errors.New("invalid token")
+
+// Claims — то, что сервис берёт из проверенного токена
+type Claims struct {
+	Subject string
+	Scopes  []string
+}
+
+// Verifier проверяет JWT и кэширует ключи JWKS
+type Verifier struct {
+	cfg    config.OIDCConfig
+	client *http.Client
+
+	mu       sync.Mutex
+	keys     map[string]crypto.PublicKey
+	loadedAt time.Time
+}
+
+// NewVerifier загружает JWKS; без OIDC_JWKS_URL и OIDC_JWKS_FILE возвращает nil — JWT не принимаются.
+// С JWKS обязательны издатель и аудитория: иначе подошёл бы любой токен того же провайдера,
+// выпущенный для другого приложения.
+func NewVerifier(ctx context.Context, cfg config.OIDCConfig) (*Verifier, error) {
+	if cfg.JWKSURL == "" && cfg.JWKSFile == "" {
+		return nil, nil
+	}
+	if cfg.Issuer == "" || cfg.Audience == "" {
+		return nil, errors.New("OIDC_ISSUER and OIDC_AUDIENCE are required when OIDC_JWKS_URL or OIDC_JWKS_FILE is set")
+	}
+
+	v := &Verifier{cfg: cfg, client: &http.Client{Timeout: 10 * time.Second}}
+	if err := v.reload(ctx); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Verify проверяет подпись и стандартные claims. Scopes read и write берутся из claim "scope" (через пробел),
+// если в нём нет ни одной из них, пользователь SSO получает обе. "scope" провайдер выдаёт любому клиенту,
+// поэтому admin из него не берётся: он даётся только участникам группы OIDC_ADMIN_GROUP.
+func (v *Verifier) Verify(ctx context.Context, raw string) (*Claims, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(validMethods),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuer(v.cfg.Issuer),
+		jwt.WithAudience(v.cfg.Audience),
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return v.key(ctx, kid)
+	}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return nil, fmt.Errorf("%w: missing sub claim", ErrInvalidToken)
+	}
+
+	result := &Claims{Subject: subject}
+	if scope, ok := claims["scope"].(string); ok {
+		for _, s := range strings.Fields(scope) {
+			if s == model.ScopeRead || s == model.ScopeWrite {
+				result.Scopes = append(result.Scopes, s)
+			}
+		}
+	}
+	if len(result.Scopes) == 0 {
+		result.Scopes = []string{model.ScopeRead, model.ScopeWrite}
+	}
+	if v.cfg.AdminGroup != "" && slices.Contains(groups(claims[v.cfg.GroupsClaim]), v.cfg.AdminGroup) {
+		result.Scopes = append(result.Scopes, model.ScopeAdmin)
+	}
+	return result, nil
+}
+
+// groups читает claim групп: провайдеры отдают его массивом строк или одной строкой через пробел
+func groups(claim interface{}) []string {
+	switch value := claim.(type) {
+	case string:
+		return strings.Fields(value)
+	case []interface{}:
+		var result []string
+		for _, item := range value {
+			if group, ok := item.(string); ok {
+				result = append(result, group)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// key ищет ключ по kid; незнакомый kid — повод перечитать JWKS (ключи ротируются), но не чаще refreshInterval
+func (v *Verifier) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if key, ok := v.lookup(kid); ok {
+		return key, nil
+	}
+	if time.Since(v.loadedAt) < refreshInterval {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	if err := v.loadLocked(ctx); err != nil {
+		return nil, err
+	}
+	if key, ok := v.lookup(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown key id %q", kid)
+}
+
+// lookup без kid подходит, только если ключ в наборе один
+func (v *Verifier) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, true
+		}
+	}
+	key, ok := v.keys[kid]
+	return key, ok
+}
+
+func (v *Verifier) reload(ctx context.Context) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.loadLocked(ctx)
+}
+
+func (v *Verifier) loadLocked(ctx context.Context) error {
+	v.loadedAt = time.Now()
+
+	data, err := v.fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to load JWKS: %w", err)
+	}
+	keys, err := ParseJWKS(data)
+	if err != nil {
+		return fmt.Errorf("unable to parse JWKS: %w", err)
+	}
+	v.keys = keys
+	return nil
+}
+
+func (v *Verifier) fetch(ctx context.Context) ([]byte, error) {
+	if v.cfg.JWKSFile != "" {
+		return os.ReadFile(v.cfg.JWKSFile)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.cfg.JWKSURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type jwkSet struct {
+	Keys []jwk `json:"keys"`
+}
+
+// ParseJWKS читает RSA- и EC-ключи подписи; ключи шифрования и прочих типов пропускаются
+func ParseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set jwkSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		var key crypto.PublicKey
+		var err error
+		switch k.Kty {
+		case "RSA":
+			key, err = rsaKey(k)
+		case "EC":
+			key, err = ecKey(k)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no signing keys")
+	}
+	return keys, nil
+}
+
+func rsaKey(k jwk) (*rsa.PublicKey, error) {
+	n, err := decodeInt(k.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := decodeInt(k.E)
+	if err != nil {
+		return nil, err
+	}
+	if !e.IsInt64() || e.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid exponent")
+	}
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+func ecKey(k jwk) (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch k.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+	}
+	x, err := decodeInt(k.X)
+	if err != nil {
+		return nil, err
+	}
+	y, err := decodeInt(k.Y)
+	if err != nil {
+		return nil, err
+	}
+	if !curve.IsOnCurve(x, y) {
+		return nil, errors.New("point is not on curve")
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+func decodeInt(value string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("invalid base64url integer")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
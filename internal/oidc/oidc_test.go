@@ -0,0 +1,223 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"review-service/internal/model"
+	"review-service/pkg/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// stubSetup создаёт ключ и JWKS локального издателя и Verifier, читающий JWKS из файла
+func stubSetup(t *testing.T) (string, config.OIDCConfig, *Verifier) {
+	t.Helper()
+	dir := t.TempDir()
+	keyPath, jwksPath := filepath.Join(dir, "stub.pem"), filepath.Join(dir, "stub.jwks.json")
+	if err := GenerateStubKey(keyPath, jwksPath); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.OIDCConfig{
+		JWKSFile:    jwksPath,
+		Issuer:      "https://idp.example.com",
+		Audience:    "review-service",
+		GroupsClaim: "groups",
+		AdminGroup:  "review-admins",
+	}
+	v, err := NewVerifier(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyPath, cfg, v
+}
+
+func issue(t *testing.T, keyPath string, cfg config.OIDCConfig, scopes, groups []string, ttl time.Duration) string {
+	t.Helper()
+	token, err := IssueStubToken(keyPath, cfg, "user-1", scopes, groups, ttl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestVerifyValidToken(t *testing.T) {
+	keyPath, cfg, v := stubSetup(t)
+
+	claims, err := v.Verify(context.Background(), issue(t, keyPath, cfg, nil, nil, time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Subject != "user-1" {
+		t.Errorf("subject = %q", claims.Subject)
+	}
+	if !slices.Equal(claims.Scopes, []string{model.ScopeRead, model.ScopeWrite}) {
+		t.Errorf("scopes = %v", claims.Scopes)
+	}
+
+	claims, err = v.Verify(context.Background(), issue(t, keyPath, cfg, []string{"read"}, nil, time.Hour))
+	if err != nil || !slices.Equal(claims.Scopes, []string{model.ScopeRead}) {
+		t.Errorf("read-only token: scopes = %v, err = %v", claims.Scopes, err)
+	}
+}
+
+func TestVerifyAdminOnlyFromGroup(t *testing.T) {
+	keyPath, cfg, v := stubSetup(t)
+
+	claims, err := v.Verify(context.Background(), issue(t, keyPath, cfg, []string{"read", "write", "admin"}, nil, time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slices.Contains(claims.Scopes, model.ScopeAdmin) {
+		t.Errorf("admin granted from the scope claim: %v", claims.Scopes)
+	}
+
+	claims, err = v.Verify(context.Background(), issue(t, keyPath, cfg, nil, []string{"developers", "review-admins"}, time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(claims.Scopes, model.ScopeAdmin) {
+		t.Errorf("admin group member did not get admin: %v", claims.Scopes)
+	}
+
+	cfg.AdminGroup = ""
+	withoutGroup, err := NewVerifier(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err = withoutGroup.Verify(context.Background(), issue(t, keyPath, cfg, nil, []string{"review-admins"}, time.Hour))
+	if err != nil || slices.Contains(claims.Scopes, model.ScopeAdmin) {
+		t.Errorf("admin granted without OIDC_ADMIN_GROUP: %v, %v", claims, err)
+	}
+}
+
+func TestVerifyRejectsInvalidTokens(t *testing.T) {
+	keyPath, cfg, v := stubSetup(t)
+	otherKeyPath, _, _ := stubSetup(t)
+
+	wrongIssuer, wrongAudience := cfg, cfg
+	wrongIssuer.Issuer = "https://evil.example.com"
+	wrongAudience.Audience = "another-app"
+
+	cases := map[string]string{
+		"bad signature":  issue(t, otherKeyPath, cfg, nil, nil, time.Hour),
+		"expired":        issue(t, keyPath, cfg, nil, nil, -time.Minute),
+		"wrong issuer":   issue(t, keyPath, wrongIssuer, nil, nil, time.Hour),
+		"wrong audience": issue(t, keyPath, wrongAudience, nil, nil, time.Hour),
+		"garbage":        "not-a-jwt",
+	}
+	for name, token := range cases {
+		if _, err := v.Verify(context.Background(), token); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: expected ErrInvalidToken, got %v", name, err)
+		}
+	}
+}
+
+func TestNewVerifierRequiresIssuerAndAudience(t *testing.T) {
+	_, cfg, _ := stubSetup(t)
+
+	for _, broken := range []config.OIDCConfig{
+		{JWKSFile: cfg.JWKSFile, Audience: cfg.Audience},
+		{JWKSFile: cfg.JWKSFile, Issuer: cfg.Issuer},
+		{JWKSURL: "https://idp.example.com/jwks"},
+	} {
+		if _, err := NewVerifier(context.Background(), broken); err == nil {
+			t.Errorf("verifier created without issuer or audience: %+v", broken)
+		}
+	}
+}
+
+// jwksServer отдаёт текущий набор ключей и считает запросы
+type jwksServer struct {
+	mu      sync.Mutex
+	keys    []jwk
+	fetches int
+}
+
+func (s *jwksServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fetches++
+	json.NewEncoder(w).Encode(jwkSet{Keys: s.keys})
+}
+
+func rsaJWK(key *rsa.PrivateKey, kid string) jwk {
+	return jwk{
+		Kty: "RSA",
+		Kid: kid,
+		Use: "sig",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func TestVerifyRefetchesJWKSOnUnknownKeyID(t *testing.T) {
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwks := &jwksServer{keys: []jwk{rsaJWK(oldKey, "old")}}
+	srv := httptest.NewServer(jwks)
+	defer srv.Close()
+
+	cfg := config.OIDCConfig{JWKSURL: srv.URL, Issuer: "https://idp.example.com", Audience: "review-service"}
+	v, err := NewVerifier(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// провайдер ротировал ключи: новый kid появился в JWKS уже после загрузки
+	jwks.mu.Lock()
+	jwks.keys = append(jwks.keys, rsaJWK(newKey, "new"))
+	jwks.mu.Unlock()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"sub": "user-1",
+		"iss": cfg.Issuer,
+		"aud": cfg.Audience,
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	token.Header["kid"] = "new"
+	raw, err := token.SignedString(newKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// сразу после загрузки JWKS не перечитывается, чтобы незнакомые kid не заваливали провайдера запросами
+	if _, err := v.Verify(context.Background(), raw); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected rejection within refresh interval, got %v", err)
+	}
+	if jwks.fetches != 1 {
+		t.Fatalf("JWKS fetched %d times, want 1", jwks.fetches)
+	}
+
+	v.mu.Lock()
+	v.loadedAt = time.Now().Add(-refreshInterval)
+	v.mu.Unlock()
+
+	claims, err := v.Verify(context.Background(), raw)
+	if err != nil {
+		t.Fatalf("token signed with the rotated key rejected: %v", err)
+	}
+	if claims.Subject != "user-1" || jwks.fetches != 2 {
+		t.Fatalf("subject = %q, fetches = %d", claims.Subject, jwks.fetches)
+	}
+}
@@ -0,0 +1,100 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"review-service/pkg/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// stubKeyID — kid ключа локального издателя
+const stubKeyID = "review-service-stub"
+
+// GenerateStubKey создаёт RSA-ключ локального издателя и JWKS для него. JWKS подключается
+// через OIDC_JWKS_FILE, токены выпускает IssueStubToken. Только для разработки и тестов.
+func GenerateStubKey(keyPath, jwksPath string) error {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		return err
+	}
+
+	set := jwkSet{Keys: []jwk{{
+		Kty: "RSA",
+		Kid: stubKeyID,
+		Use: "sig",
+		Alg: "RS256",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}}
+	data, err := json.MarshalIndent(set, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(jwksPath, data, 0o644)
+}
+
+// IssueStubToken подписывает токен ключом из GenerateStubKey; iss, aud и имя claim групп берутся из cfg
+func IssueStubToken(keyPath string, cfg config.OIDCConfig, subject string, scopes, groups []string, ttl time.Duration) (string, error) {
+	if subject == "" {
+		return "", errors.New("subject is required")
+	}
+
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		return "", err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return "", errors.New("invalid PEM key")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return "", err
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return "", errors.New("stub key must be RSA")
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"sub": subject,
+		"iat": now.Unix(),
+		"exp": now.Add(ttl).Unix(),
+	}
+	if cfg.Issuer != "" {
+		claims["iss"] = cfg.Issuer
+	}
+	if cfg.Audience != "" {
+		claims["aud"] = cfg.Audience
+	}
+	if len(scopes) > 0 {
+		claims["scope"] = strings.Join(scopes, " ")
+	}
+	if len(groups) > 0 && cfg.GroupsClaim != "" {
+		claims[cfg.GroupsClaim] = groups
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = stubKeyID
+	return token.SignedString(key)
+}
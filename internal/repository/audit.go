@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"review-service/internal/audit"
+	"review-service/internal/logging"
+	"review-service/internal/model"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// auditTarget — объект изменения в audit_log
+type auditTarget struct {
+	Type string
+	ID   string
+}
+
+func teamTarget(teamName string) auditTarget { return auditTarget{model.AuditTargetTeam, teamName} }
+func userTarget(userID string) auditTarget   { return auditTarget{model.AuditTargetUser, userID} }
+func prTarget(prID string) auditTarget       { return auditTarget{model.AuditTargetPullRequest, prID} }
+func tokenTarget(tokenID int64) auditTarget {
+	return auditTarget{model.AuditTargetAPIToken, strconv.FormatInt(tokenID, 10)}
+}
+func notificationsTarget(userID string) auditTarget {
+	return auditTarget{model.AuditTargetNotifications, userID}
+}
+func identitiesTarget(userID string) auditTarget {
+	return auditTarget{model.AuditTargetIdentities, userID}
+}
+
+// auditSnapshots — запросы состояния объекта по его ID ($1) в виде JSON; NULL, если объекта нет
+var auditSnapshots = map[string]string{
+	model.AuditTargetTeam: `
+		SELECT jsonb_build_object(
+			'team_name', t.team_name,
+			'parent_team', p.team_name,
+			'escalation', t.escalation,
+			'required_reviewer_roles', t.required_reviewer_roles,
+			'mentorship', t.mentorship,
+			'members', COALESCE((
+				SELECT jsonb_agg(jsonb_build_object('user_id', tm.user_id, 'is_primary', tm.is_primary, 'role', tm.role) ORDER BY tm.user_id)
+				FROM team_memberships tm WHERE tm.team_id = t.team_id
+			), '[]'::jsonb)
+		)
+		FROM teams t LEFT JOIN teams p ON p.team_id = t.parent_team_id
+		WHERE t.team_name = $1`,
+	model.AuditTargetUser: `
+		SELECT jsonb_build_object(
+			'user_id', u.user_id,
+			'username', u.username,
+			'is_active', u.is_active,
+			'absent_from', u.absent_from,
+			'absent_until', u.absent_until,
+			'teams', COALESCE((
+				SELECT jsonb_agg(jsonb_build_object('team_name', t.team_name, 'is_primary', tm.is_primary, 'role', tm.role) ORDER BY t.team_name)
+				FROM team_memberships tm JOIN teams t ON t.team_id = tm.team_id WHERE tm.user_id = u.user_id
+			), '[]'::jsonb)
+		)
+		FROM users u WHERE u.user_id = $1`,
+	model.AuditTargetPullRequest: `
+		SELECT to_jsonb(pr) || jsonb_build_object(
+			'reviewers', COALESCE((
+				SELECT jsonb_agg(jsonb_build_object('user_id', prr.user_id, 'is_shadow', prr.is_shadow, 'verdict', prr.verdict) ORDER BY prr.user_id)
+				FROM pr_reviewers prr WHERE prr.pull_request_id = pr.pull_request_id
+			), '[]'::jsonb),
+			'link', (SELECT to_jsonb(l) - 'pull_request_id' FROM pull_request_links l WHERE l.pull_request_id = pr.pull_request_id)
+		)
+		FROM pull_requests pr WHERE pr.pull_request_id = $1`,
+	model.AuditTargetNotifications: `
+		SELECT jsonb_agg(to_jsonb(np) ORDER BY np.channel) FROM notification_preferences np WHERE np.user_id = $1`,
+	model.AuditTargetIdentities: `
+		SELECT jsonb_agg(to_jsonb(ui) ORDER BY ui.provider, ui.external_login) FROM user_identities ui WHERE ui.user_id = $1`,
+	model.AuditTargetAPIToken: `
+		SELECT to_jsonb(t) - 'token_hash' FROM api_tokens t WHERE t.token_id::text = $1`,
+}
+
+// snapshot читает состояние объекта в транзакции изменения
+func snapshot(ctx context.Context, tx pgx.Tx, target auditTarget) ([]byte, error) {
+	query, ok := auditSnapshots[target.Type]
+	if !ok {
+		return nil, nil
+	}
+	var state []byte
+	err := tx.QueryRow(ctx, query, target.ID).Scan(&state)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return state, err
+}
+
+// writeAudit снимает состояние объекта после изменения и пишет запись аудита в ту же транзакцию.
+// Операции, не изменившие объект (повторная привязка, повторный отзыв), в журнал не попадают.
+func writeAudit(ctx context.Context, tx pgx.Tx, action string, target auditTarget, before []byte) error {
+	after, err := snapshot(ctx, tx, target)
+	if err != nil {
+		return err
+	}
+	if before != nil && bytes.Equal(before, after) {
+		return nil
+	}
+	return insertAudit(ctx, tx, action, target, before, after)
+}
+
+func insertAudit(ctx context.Context, tx pgx.Tx, action string, target auditTarget, before, after []byte) error {
+	actor := audit.ActorFromContext(ctx)
+	_, err := tx.Exec(ctx, `
+		INSERT INTO audit_log (actor_type, actor_id, action, target_type, target_id, before, after, request_id) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''))
+	`, actor.Type, actor.ID, action, target.Type, target.ID, before, after, logging.RequestID(ctx))
+	return err
+}
+
+// audited выполняет fn в транзакции и в ней же пишет запись аудита с состоянием target до и после
+func (r *postgresRepository) audited(ctx context.Context, action string, target auditTarget, fn func(tx pgx.Tx) error) error {
+	tx, err := r.db(ctx).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshot(ctx, tx, target)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := writeAudit(ctx, tx, action, target, before); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ListAuditLog возвращает страницу записей аудита, новые первыми, и общее число подходящих под фильтр
+func (r *postgresRepository) ListAuditLog(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, int, error) {
+	const where = `
+		WHERE ($1 = '' OR actor_id = $1)
+			AND ($2 = '' OR action = $2)
+			AND ($3 = '' OR target_type = $3)
+			AND ($4 = '' OR target_id = $4)
+			AND ($5 = '' OR request_id = $5)
+			AND ($6::timestamptz IS NULL OR occurred_at >= $6)
+			AND ($7::timestamptz IS NULL OR occurred_at < $7)
+	`
+	args := []interface{}{filter.ActorID, filter.Action, filter.TargetType, filter.TargetID, filter.RequestID, filter.From, filter.To}
+
+	var total int
+	if err := r.db(ctx).QueryRow(ctx, "SELECT COUNT(*) FROM audit_log"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db(ctx).Query(ctx, `
+		SELECT audit_id, occurred_at, actor_type, actor_id, action, target_type, target_id, before, after, COALESCE(request_id, '')
+		FROM audit_log`+where+`
+		ORDER BY audit_id DESC
+		LIMIT $8 OFFSET $9
+	`, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := []*model.AuditEntry{}
+	for rows.Next() {
+		var entry model.AuditEntry
+		var before, after []byte
+		err := rows.Scan(&entry.AuditID, &entry.OccurredAt, &entry.ActorType, &entry.ActorID, &entry.Action,
+			&entry.TargetType, &entry.TargetID, &before, &after, &entry.RequestID)
+		if err != nil {
+			return nil, 0, err
+		}
+		entry.Before, entry.After = json.RawMessage(before), json.RawMessage(after)
+		entries = append(entries, &entry)
+	}
+
+	return entries, total, rows.Err()
+}
@@ -1,14 +1,37 @@
 package repository
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
 
 var (
-	ErrTeamExists      = errors.New("team already exists")
-	ErrTeamNotFound    = errors.New("team not found")
-	ErrUserNotFound    = errors.New("user not found")
-	ErrPRNotFound      = errors.New("pull request not found")
-	ErrPRExists        = errors.New("pull request already exists")
-	ErrPRMerged        = errors.New("pull request is merged")
-	ErrUserNotAssigned = errors.New("user is not assigned as reviewer")
-	ErrNoActiveUsers   = errors.New("no active users available")
-)
\ No newline at end of file
+	ErrTeamExists          = errors.New("team already exists")
+	ErrTeamNotFound        = errors.New("team not found")
+	ErrTeamCycle           = errors.New("team hierarchy cycle")
+	ErrTeamHasSubTeams     = errors.New("team has sub-teams")
+	ErrTeamHasOpenPRs      = errors.New("team has open pull requests")
+	ErrDestinationRequired = errors.New("destination team is required")
+	ErrUserNotFound        = errors.New("user not found")
+	ErrUserExists          = errors.New("user already exists")
+	ErrUserInOtherTeam     = errors.New("user belongs to another team")
+	ErrUserNotInTeam       = errors.New("user is not a member of team")
+	ErrAlreadyTeamMember   = errors.New("user is already a member of team")
+	ErrPRNotFound          = errors.New("pull request not found")
+	ErrPRExists            = errors.New("pull request already exists")
+	ErrPRMerged            = errors.New("pull request is merged")
+	ErrPRClosed            = errors.New("pull request is closed")
+	ErrUserNotAssigned     = errors.New("user is not assigned as reviewer")
+	ErrAlreadyReviewer     = errors.New("user is already a reviewer of pull request")
+	ErrNoActiveUsers       = errors.New("no active users available")
+	ErrIdentityNotFound    = errors.New("identity not found")
+	ErrPRLinkNotFound      = errors.New("pull request link not found")
+	ErrTokenNotFound       = errors.New("api token not found")
+)
+
+// isUniqueViolation сообщает, что запрос нарушил уникальный индекс (SQLSTATE 23505)
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"review-service/internal/audit"
+	"review-service/internal/model"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// recordPREvent пишет событие истории PR в транзакции изменения; действующее лицо берётся из контекста
+func recordPREvent(ctx context.Context, tx pgx.Tx, prID string, event model.PullRequestHistoryEvent) error {
+	actor := audit.ActorFromContext(ctx)
+	_, err := tx.Exec(ctx, `
+		INSERT INTO pr_events (pull_request_id, event_type, user_id, old_user_id, is_shadow, reason, verdict, actor_type, actor_id) 
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5, NULLIF($6, ''), NULLIF($7, ''), $8, $9)
+	`, prID, event.Type, event.UserID, event.OldUserID, event.IsShadow, event.Reason, event.Verdict, actor.Type, actor.ID)
+	return err
+}
+
+// GetPullRequestHistory возвращает события PR в порядке их записи; с until — только произошедшие не позже него
+func (r *postgresRepository) GetPullRequestHistory(ctx context.Context, prID string, until *time.Time) ([]*model.PullRequestHistoryEvent, error) {
+	rows, err := r.db(ctx).Query(ctx, `
+		SELECT event_id, event_type, COALESCE(user_id, ''), COALESCE(old_user_id, ''), is_shadow, COALESCE(reason, ''), 
+			COALESCE(verdict, ''), actor_type, actor_id, occurred_at
+		FROM pr_events 
+		WHERE pull_request_id = $1 AND ($2::timestamptz IS NULL OR occurred_at <= $2)
+		ORDER BY occurred_at, event_id
+	`, prID, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []*model.PullRequestHistoryEvent{}
+	for rows.Next() {
+		var event model.PullRequestHistoryEvent
+		err := rows.Scan(&event.EventID, &event.Type, &event.UserID, &event.OldUserID, &event.IsShadow, &event.Reason,
+			&event.Verdict, &event.ActorType, &event.ActorID, &event.OccurredAt)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, &event)
+	}
+
+	return events, rows.Err()
+}
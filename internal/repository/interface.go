@@ -3,20 +3,37 @@ package repository
 import (
 	"context"
 	"review-service/internal/model"
+	"time"
 )
 
 // TeamRepository интерфейс для работы с командами
 type TeamRepository interface {
-	CreateTeam(ctx context.Context, team *model.Team) error
+	CreateTeam(ctx context.Context, team *model.Team, conflictPolicy string) ([]model.MemberOutcome, error)
 	GetTeam(ctx context.Context, teamName string) (*model.Team, error)
 	TeamExists(ctx context.Context, teamName string) (bool, error)
+	SetTeamParent(ctx context.Context, teamName, parentTeam string) error
+	SetTeamEscalation(ctx context.Context, teamName, escalation string) error
+	SetTeamReviewerRoles(ctx context.Context, teamName string, roles []string) error
+	SetTeamMentorship(ctx context.Context, teamName string, enabled bool) error
+	ApplyImport(ctx context.Context, teams []model.ImportTeam) error
+	SetMemberRole(ctx context.Context, teamName, userID, role string) error
+	GetSubTeams(ctx context.Context, parentTeam string) ([]string, error)
+	ListTeamHierarchy(ctx context.Context) ([]*model.Team, error)
+	ListTeams(ctx context.Context, filter model.TeamListFilter) ([]*model.TeamSummary, int, error)
+	AddTeamMember(ctx context.Context, teamName string, member *model.TeamMember) error
+	ChangeUserTeam(ctx context.Context, userID string, fromTeam, toTeam string, replacements []model.ReviewerReplacement) (*model.User, error)
+	RenameTeam(ctx context.Context, teamName, newName string) error
+	DeleteTeam(ctx context.Context, teamName, destinationTeam string, reparentSubTeams bool) error
 }
 
 // UserRepository интерфейс для работы с пользователями
 type UserRepository interface {
 	CreateUser(ctx context.Context, user *model.User) error
 	GetUser(ctx context.Context, userID string) (*model.User, error)
+	ListUsers(ctx context.Context, filter model.UserFilter) ([]*model.User, int, error)
+	UpdateUser(ctx context.Context, update *model.UserUpdate) (*model.User, error)
 	SetUserActive(ctx context.Context, userID string, isActive bool) (*model.User, error)
+	SetUserAbsence(ctx context.Context, userID string, from, until *time.Time) (*model.User, error)
 	GetActiveUsersByTeam(ctx context.Context, teamName string, excludeUserID string) ([]*model.User, error)
 	UserExists(ctx context.Context, userID string) (bool, error)
 }
@@ -26,15 +43,73 @@ type PullRequestRepository interface {
 	CreatePullRequest(ctx context.Context, pr *model.PullRequest, reviewers []string) error
 	GetPullRequest(ctx context.Context, prID string) (*model.PullRequest, error)
 	MergePullRequest(ctx context.Context, prID string) error
+	ClosePullRequest(ctx context.Context, prID string) error
+	ReopenPullRequest(ctx context.Context, prID string) error
 	ReassignReviewer(ctx context.Context, prID string, oldUserID string, newUserID string) error
 	GetUserReviewRequests(ctx context.Context, userID string) ([]*model.PullRequestShort, error)
 	PRExists(ctx context.Context, prID string) (bool, error)
 	IsUserAssignedToPR(ctx context.Context, prID string, userID string) (bool, error)
+	SubmitVerdict(ctx context.Context, prID, userID, verdict string) error
+	AddShadowReviewer(ctx context.Context, prID, userID string) error
+	RemoveShadowReviewer(ctx context.Context, prID, userID string) error
+	GetPullRequestHistory(ctx context.Context, prID string, until *time.Time) ([]*model.PullRequestHistoryEvent, error)
+}
+
+// IntegrationRepository интерфейс для сопоставления внешних аккаунтов и учёта доставок вебхуков
+type IntegrationRepository interface {
+	SetUserIdentity(ctx context.Context, identity *model.UserIdentity) error
+	GetUserIDByIdentity(ctx context.Context, provider, externalLogin string) (string, error)
+	GetUserIDByExternalID(ctx context.Context, provider, externalID string) (string, error)
+	RegisterWebhookDelivery(ctx context.Context, provider, deliveryID string) (bool, error)
+	GetIdentityLogins(ctx context.Context, provider string, userIDs []string) ([]string, error)
+	SetPullRequestLink(ctx context.Context, link *model.PullRequestLink) error
+	GetPullRequestLink(ctx context.Context, prID string) (*model.PullRequestLink, error)
+	EnqueueReviewerSync(ctx context.Context, prID string, action string, userIDs []string) error
+	ClaimReviewerSyncJobs(ctx context.Context, limit, maxAttempts int, lease time.Duration) ([]*model.ReviewerSyncJob, error)
+	CompleteReviewerSyncJob(ctx context.Context, jobID int64) error
+	FailReviewerSyncJob(ctx context.Context, jobID int64, cause string, retryAt time.Time) error
+}
+
+// NotificationRepository интерфейс для работы с настройками уведомлений
+type NotificationRepository interface {
+	SetNotificationPreference(ctx context.Context, pref *model.NotificationPreference) error
+	DeleteNotificationPreference(ctx context.Context, userID, channel string) error
+	GetNotificationPreferences(ctx context.Context, userIDs []string) ([]*model.NotificationPreference, error)
+	GetDigestSubscribers(ctx context.Context, channel string) ([]*model.NotificationPreference, error)
+}
+
+// StatsRepository агрегаты по ревью для отчётов
+type StatsRepository interface {
+	GetReviewerStats(ctx context.Context, filter model.StatsFilter) ([]*model.ReviewerStats, error)
+	GetTeamStats(ctx context.Context, filter model.StatsFilter) ([]*model.TeamStats, error)
+	CountOpenReviewsByTeam(ctx context.Context) (map[string]int, error)
+}
+
+// TokenRepository API-токены; токены ищутся по SHA-256, сам токен не хранится
+type TokenRepository interface {
+	CreateAPIToken(ctx context.Context, token *model.APIToken, tokenHash string) error
+	AuthenticateAPIToken(ctx context.Context, tokenHash string) (*model.APIToken, error)
+	GetAPIToken(ctx context.Context, tokenID int64) (*model.APIToken, error)
+	ListAPITokens(ctx context.Context, userID string) ([]*model.APIToken, error)
+	RevokeAPIToken(ctx context.Context, tokenID int64, ownerID string) error
+}
+
+// AuditRepository чтение журнала аудита; записи добавляются самими изменяющими методами
+type AuditRepository interface {
+	ListAuditLog(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, int, error)
 }
 
 // Объединяющий интерфейс
 type Repository interface {
+	// InTx выполняет fn в одной транзакции для всех вызовов репозитория с переданным в fn контекстом
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+
 	TeamRepository
 	UserRepository
 	PullRequestRepository
+	IntegrationRepository
+	NotificationRepository
+	StatsRepository
+	TokenRepository
+	AuditRepository
 }
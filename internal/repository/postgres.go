@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"review-service/internal/model"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -16,310 +17,1964 @@ func NewPostgresRepository(pool *pgxpool.Pool) Repository {
 	return &postgresRepository{pool: pool}
 }
 
-func (r *postgresRepository) CreateTeam(ctx context.Context, team *model.Team) error {
-	tx, err := r.pool.Begin(ctx)
+// CreateTeam создаёт команду и её участников. Участники, у которых уже есть основная команда,
+// обрабатываются по conflictPolicy: reject — вся операция отменяется с ErrUserInOtherTeam,
+// move — новая команда становится основной вместо прежней, join — пользователь добавляется
+// в новую команду дополнительно, skip — остаётся где был. Имя и активность существующих
+// пользователей не меняются. Пользователи без основной команды всегда присоединяются к новой как к основной.
+func (r *postgresRepository) CreateTeam(ctx context.Context, team *model.Team, conflictPolicy string) ([]model.MemberOutcome, error) {
+	tx, err := r.db(ctx).Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO teams (team_name, parent_team_id, escalation, required_reviewer_roles, mentorship) 
+		VALUES ($1, (SELECT team_id FROM teams WHERE team_name = NULLIF($2, '')), COALESCE(NULLIF($3, ''), 'none'), COALESCE($4, '{}'), $5)
+	`, team.TeamName, team.ParentTeam, team.Escalation, team.RequiredReviewerRoles, team.Mentorship)
+	if err != nil {
+		return nil, ErrTeamExists
+	}
+
+	outcomes := make([]model.MemberOutcome, 0, len(team.Members))
+	conflict := false
+	for _, member := range team.Members {
+		outcome := model.MemberOutcome{UserID: member.UserID}
+
+		var exists bool
+		var primaryTeam string
+		err = tx.QueryRow(ctx, `
+			SELECT TRUE, COALESCE(t.team_name, '')
+			FROM users u
+			LEFT JOIN team_memberships tm ON tm.user_id = u.user_id AND tm.is_primary
+			LEFT JOIN teams t ON t.team_id = tm.team_id
+			WHERE u.user_id = $1
+			FOR UPDATE OF u
+		`, member.UserID).Scan(&exists, &primaryTeam)
+		if err != nil && err != pgx.ErrNoRows {
+			return nil, err
+		}
+		outcome.PreviousTeam = primaryTeam
+
+		switch {
+		case !exists:
+			_, err = tx.Exec(ctx, `
+				INSERT INTO users (user_id, username, is_active) 
+				VALUES ($1, $2, $3)
+			`, member.UserID, member.Username, member.IsActive)
+			if err == nil {
+				err = addMembership(ctx, tx, member.UserID, team.TeamName, true, member.Role)
+			}
+			outcome.Outcome = model.MemberCreated
+		case primaryTeam == "":
+			err = addMembership(ctx, tx, member.UserID, team.TeamName, true, member.Role)
+			outcome.Outcome = model.MemberMoved
+		case conflictPolicy == model.ConflictPolicyReject:
+			outcome.Outcome = model.MemberConflict
+			conflict = true
+		case conflictPolicy == model.ConflictPolicySkip:
+			outcome.Outcome = model.MemberSkipped
+		case conflictPolicy == model.ConflictPolicyJoin:
+			err = addMembership(ctx, tx, member.UserID, team.TeamName, false, member.Role)
+			outcome.Outcome = model.MemberJoined
+		default:
+			_, err = tx.Exec(ctx, "DELETE FROM team_memberships WHERE user_id = $1 AND is_primary", member.UserID)
+			if err == nil {
+				err = addMembership(ctx, tx, member.UserID, team.TeamName, true, member.Role)
+			}
+			outcome.Outcome = model.MemberMoved
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		outcomes = append(outcomes, outcome)
+	}
+
+	if conflict {
+		return outcomes, ErrUserInOtherTeam
+	}
+	if err := writeAudit(ctx, tx, "team.create", teamTarget(team.TeamName), nil); err != nil {
+		return nil, err
+	}
+
+	return outcomes, tx.Commit(ctx)
+}
+
+// addMembership добавляет пользователя в команду; пустая роль означает member
+func addMembership(ctx context.Context, tx pgx.Tx, userID, teamName string, isPrimary bool, role string) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO team_memberships (user_id, team_id, is_primary, role) 
+		SELECT $1, team_id, $3, COALESCE(NULLIF($4, ''), 'member') FROM teams WHERE team_name = $2
+		ON CONFLICT (user_id, team_id) DO UPDATE SET
+			is_primary = team_memberships.is_primary OR EXCLUDED.is_primary,
+			role = EXCLUDED.role
+	`, userID, teamName, isPrimary, role)
+	return err
+}
+
+func (r *postgresRepository) GetTeam(ctx context.Context, teamName string) (*model.Team, error) {
+	var team model.Team
+	err := r.db(ctx).QueryRow(ctx, `
+		SELECT t.team_name, COALESCE(p.team_name, ''), t.escalation, t.required_reviewer_roles, t.mentorship 
+		FROM teams t 
+		LEFT JOIN teams p ON p.team_id = t.parent_team_id
+		WHERE t.team_name = $1
+	`, teamName).Scan(&team.TeamName, &team.ParentTeam, &team.Escalation, &team.RequiredReviewerRoles, &team.Mentorship)
+	if err == pgx.ErrNoRows {
+		return nil, ErrTeamNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db(ctx).Query(ctx, `
+		SELECT u.user_id, u.username, u.is_active, tm.is_primary, tm.role, u.absent_from, u.absent_until 
+		FROM team_memberships tm
+		JOIN teams t ON t.team_id = tm.team_id
+		JOIN users u ON u.user_id = tm.user_id
+		WHERE t.team_name = $1
+		ORDER BY u.user_id
+	`, teamName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var member model.TeamMember
+		err := rows.Scan(&member.UserID, &member.Username, &member.IsActive, &member.IsPrimary, &member.Role,
+			&member.AbsentFrom, &member.AbsentUntil)
+		if err != nil {
+			return nil, err
+		}
+		team.Members = append(team.Members, member)
+	}
+
+	if team.Members == nil {
+		team.Members = []model.TeamMember{}
+	}
+
+	return &team, nil
+}
+
+// AddTeamMember добавляет участника в команду. Новый пользователь создаётся с этой командой как основной;
+// существующий получает дополнительное членство (или основное, если основной команды у него нет).
+func (r *postgresRepository) AddTeamMember(ctx context.Context, teamName string, member *model.TeamMember) error {
+	tx, err := r.db(ctx).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshot(ctx, tx, teamTarget(teamName))
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO users (user_id, username, is_active) 
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO NOTHING
+	`, member.UserID, member.Username, member.IsActive)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(ctx, `
+		INSERT INTO team_memberships (user_id, team_id, is_primary, role) 
+		SELECT $1, team_id, NOT EXISTS(SELECT 1 FROM team_memberships WHERE user_id = $1 AND is_primary), 
+			COALESCE(NULLIF($3, ''), 'member') 
+		FROM teams WHERE team_name = $2
+		ON CONFLICT (user_id, team_id) DO NOTHING
+	`, member.UserID, teamName, member.Role)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrAlreadyTeamMember
+	}
+	if err := writeAudit(ctx, tx, "team.add_member", teamTarget(teamName), before); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ChangeUserTeam выводит пользователя из команды fromTeam и, если toTeam не пуст, делает toTeam его основной командой.
+// Если покинутая команда была основной и новой не задано, основной становится самая ранняя из оставшихся.
+// В той же транзакции применяются замены ревьюеров; замена с пустым NewUserID снимает пользователя с ревью.
+func (r *postgresRepository) ChangeUserTeam(ctx context.Context, userID string, fromTeam, toTeam string, replacements []model.ReviewerReplacement) (*model.User, error) {
+	tx, err := r.db(ctx).Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshot(ctx, tx, userTarget(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	var wasPrimary bool
+	if fromTeam != "" {
+		err = tx.QueryRow(ctx, `
+			DELETE FROM team_memberships 
+			WHERE user_id = $1 AND team_id = (SELECT team_id FROM teams WHERE team_name = $2) 
+			RETURNING is_primary
+		`, userID, fromTeam).Scan(&wasPrimary)
+		if err == pgx.ErrNoRows {
+			return nil, ErrUserNotInTeam
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if toTeam != "" {
+		_, err = tx.Exec(ctx, "UPDATE team_memberships SET is_primary = FALSE WHERE user_id = $1 AND is_primary", userID)
+		if err == nil {
+			_, err = tx.Exec(ctx, `
+				INSERT INTO team_memberships (user_id, team_id, is_primary) 
+				SELECT $1, team_id, TRUE FROM teams WHERE team_name = $2
+				ON CONFLICT (user_id, team_id) DO UPDATE SET is_primary = TRUE
+			`, userID, toTeam)
+		}
+	} else if wasPrimary {
+		_, err = tx.Exec(ctx, `
+			UPDATE team_memberships SET is_primary = TRUE 
+			WHERE user_id = $1 AND team_id = (
+				SELECT team_id FROM team_memberships 
+				WHERE user_id = $1 
+				ORDER BY joined_at, team_id 
+				LIMIT 1
+			)
+		`, userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeAudit(ctx, tx, "user.change_team", userTarget(userID), before); err != nil {
+		return nil, err
+	}
+
+	for _, replacement := range replacements {
+		prBefore, err := snapshot(ctx, tx, prTarget(replacement.PullRequestID))
+		if err != nil {
+			return nil, err
+		}
+		var isShadow bool
+		if replacement.NewUserID == "" {
+			err = tx.QueryRow(ctx, `
+				DELETE FROM pr_reviewers 
+				WHERE pull_request_id = $1 AND user_id = $2
+				RETURNING is_shadow
+			`, replacement.PullRequestID, userID).Scan(&isShadow)
+		} else {
+			err = tx.QueryRow(ctx, `
+				UPDATE pr_reviewers 
+				SET user_id = $1, assigned_at = NOW(), verdict = NULL, verdict_at = NULL
+				WHERE pull_request_id = $2 AND user_id = $3
+				RETURNING is_shadow
+			`, replacement.NewUserID, replacement.PullRequestID, userID).Scan(&isShadow)
+		}
+		if err == pgx.ErrNoRows {
+			// ревьюера уже сняли с PR параллельно — менять и записывать нечего
+			continue
+		}
+		if err == nil {
+			err = recordReassignment(ctx, tx, replacement.PullRequestID, userID, replacement.NewUserID, "team_change", isShadow)
+		}
+		if err == nil {
+			err = writeAudit(ctx, tx, "pull_request.reassign", prTarget(replacement.PullRequestID), prBefore)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return r.GetUser(ctx, userID)
+}
+
+// SetTeamParent перевешивает команду под parentTeam (пустая строка — сделать корневой).
+// Возвращает ErrTeamCycle, если parentTeam — сама команда или её потомок.
+func (r *postgresRepository) SetTeamParent(ctx context.Context, teamName, parentTeam string) error {
+	tx, err := r.db(ctx).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Сериализуем изменения иерархии, чтобы два встречных перевешивания не образовали цикл
+	if _, err := tx.Exec(ctx, "LOCK TABLE teams IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+		return err
+	}
+
+	before, err := snapshot(ctx, tx, teamTarget(teamName))
+	if err != nil {
+		return err
+	}
+
+	if parentTeam != "" {
+		var cycle bool
+		err = tx.QueryRow(ctx, `
+			WITH RECURSIVE ancestors AS (
+				SELECT team_id, team_name, parent_team_id FROM teams WHERE team_name = $1
+				UNION
+				SELECT t.team_id, t.team_name, t.parent_team_id 
+				FROM teams t 
+				JOIN ancestors a ON t.team_id = a.parent_team_id
+			)
+			SELECT EXISTS(SELECT 1 FROM ancestors WHERE team_name = $2)
+		`, parentTeam, teamName).Scan(&cycle)
+		if err != nil {
+			return err
+		}
+		if cycle {
+			return ErrTeamCycle
+		}
+	}
+
+	result, err := tx.Exec(ctx, `
+		UPDATE teams 
+		SET parent_team_id = (SELECT team_id FROM teams WHERE team_name = NULLIF($1, '')) 
+		WHERE team_name = $2
+	`, parentTeam, teamName)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrTeamNotFound
+	}
+	if err := writeAudit(ctx, tx, "team.set_parent", teamTarget(teamName), before); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ApplyImport применяет разобранный файл импорта в одной транзакции: создаёт или обновляет команды,
+// пользователей и членства. Членства, не упомянутые в импорте, не трогаются.
+// Итоговая иерархия проверяется на циклы целиком, после перевешивания всех команд.
+func (r *postgresRepository) ApplyImport(ctx context.Context, teams []model.ImportTeam) error {
+	tx, err := r.db(ctx).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "LOCK TABLE teams IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+		return err
+	}
+
+	before := make([][]byte, len(teams))
+	for i, team := range teams {
+		if before[i], err = snapshot(ctx, tx, teamTarget(team.TeamName)); err != nil {
+			return err
+		}
+	}
+
+	for _, team := range teams {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO teams (team_name, escalation, required_reviewer_roles, mentorship) 
+			VALUES ($1, COALESCE(NULLIF($2, ''), 'none'), COALESCE($3, '{}'), COALESCE($4, FALSE))
+			ON CONFLICT (team_name) DO UPDATE SET
+				escalation = COALESCE(NULLIF($2, ''), teams.escalation),
+				required_reviewer_roles = COALESCE($3, teams.required_reviewer_roles),
+				mentorship = COALESCE($4, teams.mentorship)
+		`, team.TeamName, team.Escalation, team.RequiredReviewerRoles, team.Mentorship)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, team := range teams {
+		if team.ParentTeam == "" {
+			continue
+		}
+		_, err = tx.Exec(ctx, `
+			UPDATE teams 
+			SET parent_team_id = (SELECT team_id FROM teams WHERE team_name = $1) 
+			WHERE team_name = $2
+		`, team.ParentTeam, team.TeamName)
+		if err != nil {
+			return err
+		}
+	}
+
+	var cycle bool
+	err = tx.QueryRow(ctx, `
+		WITH RECURSIVE walk AS (
+			SELECT team_id AS start_id, parent_team_id AS ancestor_id, 1 AS depth 
+			FROM teams WHERE parent_team_id IS NOT NULL
+			UNION ALL
+			SELECT w.start_id, t.parent_team_id, w.depth + 1 
+			FROM walk w 
+			JOIN teams t ON t.team_id = w.ancestor_id
+			WHERE w.ancestor_id <> w.start_id AND t.parent_team_id IS NOT NULL 
+				AND w.depth < (SELECT COUNT(*) FROM teams)
+		)
+		SELECT EXISTS(SELECT 1 FROM walk WHERE ancestor_id = start_id)
+	`).Scan(&cycle)
+	if err != nil {
+		return err
+	}
+	if cycle {
+		return ErrTeamCycle
+	}
+
+	for _, team := range teams {
+		for _, member := range team.Members {
+			_, err = tx.Exec(ctx, `
+				INSERT INTO users (user_id, username, is_active) 
+				VALUES ($1, $2, COALESCE($3, TRUE))
+				ON CONFLICT (user_id) DO UPDATE SET
+					username = COALESCE(NULLIF($2, ''), users.username),
+					is_active = COALESCE($3, users.is_active),
+					updated_at = NOW()
+			`, member.UserID, member.Username, member.IsActive)
+			if err != nil {
+				return err
+			}
+
+			if member.IsPrimary {
+				_, err = tx.Exec(ctx, `
+					UPDATE team_memberships SET is_primary = FALSE 
+					WHERE user_id = $1 AND is_primary 
+						AND team_id <> (SELECT team_id FROM teams WHERE team_name = $2)
+				`, member.UserID, team.TeamName)
+				if err != nil {
+					return err
+				}
+			}
+
+			_, err = tx.Exec(ctx, `
+				INSERT INTO team_memberships (user_id, team_id, is_primary, role) 
+				SELECT $1, team_id, $3, COALESCE(NULLIF($4, ''), 'member') FROM teams WHERE team_name = $2
+				ON CONFLICT (user_id, team_id) DO UPDATE SET
+					is_primary = EXCLUDED.is_primary,
+					role = COALESCE(NULLIF($4, ''), team_memberships.role)
+			`, member.UserID, team.TeamName, member.IsPrimary, member.Role)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for i, team := range teams {
+		if err := writeAudit(ctx, tx, "team.import", teamTarget(team.TeamName), before[i]); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *postgresRepository) SetTeamEscalation(ctx context.Context, teamName, escalation string) error {
+	return r.audited(ctx, "team.set_escalation", teamTarget(teamName), func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, "UPDATE teams SET escalation = $1 WHERE team_name = $2", escalation, teamName)
+		if err != nil {
+			return err
+		}
+		if result.RowsAffected() == 0 {
+			return ErrTeamNotFound
+		}
+		return nil
+	})
+}
+
+func (r *postgresRepository) GetSubTeams(ctx context.Context, parentTeam string) ([]string, error) {
+	rows, err := r.db(ctx).Query(ctx, `
+		SELECT c.team_name 
+		FROM teams c 
+		JOIN teams p ON p.team_id = c.parent_team_id
+		WHERE p.team_name = $1 
+		ORDER BY c.team_name
+	`, parentTeam)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var teams []string
+	for rows.Next() {
+		var teamName string
+		if err := rows.Scan(&teamName); err != nil {
+			return nil, err
+		}
+		teams = append(teams, teamName)
+	}
+
+	return teams, rows.Err()
+}
+
+// ListTeamHierarchy возвращает все команды без участников, отсортированные по имени
+func (r *postgresRepository) ListTeamHierarchy(ctx context.Context) ([]*model.Team, error) {
+	rows, err := r.db(ctx).Query(ctx, `
+		SELECT t.team_name, COALESCE(p.team_name, ''), t.escalation 
+		FROM teams t 
+		LEFT JOIN teams p ON p.team_id = t.parent_team_id
+		ORDER BY t.team_name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var teams []*model.Team
+	for rows.Next() {
+		var team model.Team
+		if err := rows.Scan(&team.TeamName, &team.ParentTeam, &team.Escalation); err != nil {
+			return nil, err
+		}
+		teams = append(teams, &team)
+	}
+
+	return teams, rows.Err()
+}
+
+func (r *postgresRepository) SetMemberRole(ctx context.Context, teamName, userID, role string) error {
+	return r.audited(ctx, "team.set_member_role", teamTarget(teamName), func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, `
+			UPDATE team_memberships SET role = $1 
+			WHERE user_id = $2 AND team_id = (SELECT team_id FROM teams WHERE team_name = $3)
+		`, role, userID, teamName)
+		if err != nil {
+			return err
+		}
+		if result.RowsAffected() == 0 {
+			return ErrUserNotInTeam
+		}
+		return nil
+	})
+}
+
+func (r *postgresRepository) SetTeamMentorship(ctx context.Context, teamName string, enabled bool) error {
+	return r.audited(ctx, "team.set_mentorship", teamTarget(teamName), func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, "UPDATE teams SET mentorship = $1 WHERE team_name = $2", enabled, teamName)
+		if err != nil {
+			return err
+		}
+		if result.RowsAffected() == 0 {
+			return ErrTeamNotFound
+		}
+		return nil
+	})
+}
+
+func (r *postgresRepository) SetTeamReviewerRoles(ctx context.Context, teamName string, roles []string) error {
+	return r.audited(ctx, "team.set_reviewer_roles", teamTarget(teamName), func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, "UPDATE teams SET required_reviewer_roles = $1 WHERE team_name = $2", roles, teamName)
+		if err != nil {
+			return err
+		}
+		if result.RowsAffected() == 0 {
+			return ErrTeamNotFound
+		}
+		return nil
+	})
+}
+
+// teamSortColumns сопоставляет ключи сортировки списка команд с колонками запроса ListTeams
+var teamSortColumns = map[string]string{
+	model.TeamSortName:          "team_name",
+	model.TeamSortMembers:       "members",
+	model.TeamSortActiveMembers: "active_members",
+	model.TeamSortOpenReviews:   "open_reviews",
+}
+
+// ListTeams возвращает страницу сводок по командам и общее число команд.
+// При равенстве ключа сортировки команды упорядочиваются по имени.
+func (r *postgresRepository) ListTeams(ctx context.Context, filter model.TeamListFilter) ([]*model.TeamSummary, int, error) {
+	column, ok := teamSortColumns[filter.SortBy]
+	if !ok {
+		column = teamSortColumns[model.TeamSortName]
+	}
+	direction := "ASC"
+	if filter.Descending {
+		direction = "DESC"
+	}
+
+	var total int
+	if err := r.db(ctx).QueryRow(ctx, "SELECT COUNT(*) FROM teams").Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db(ctx).Query(ctx, `
+		SELECT team_name, parent_team, members, active_members, open_reviews FROM (
+			SELECT t.team_name, COALESCE(p.team_name, '') AS parent_team,
+				(SELECT COUNT(*) FROM team_memberships tm WHERE tm.team_id = t.team_id) AS members,
+				(
+					SELECT COUNT(*) FROM team_memberships tm JOIN users u ON u.user_id = tm.user_id 
+					WHERE tm.team_id = t.team_id AND u.is_active
+				) AS active_members,
+				(
+					SELECT COUNT(*) FROM team_memberships tm 
+					JOIN pr_reviewers prr ON prr.user_id = tm.user_id AND NOT prr.is_shadow
+					JOIN pull_requests pr ON pr.pull_request_id = prr.pull_request_id
+					WHERE tm.team_id = t.team_id AND pr.status = 'OPEN'
+				) AS open_reviews
+			FROM teams t 
+			LEFT JOIN teams p ON p.team_id = t.parent_team_id
+		) summary
+		ORDER BY `+column+` `+direction+`, team_name
+		LIMIT $1 OFFSET $2
+	`, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	teams := []*model.TeamSummary{}
+	for rows.Next() {
+		var team model.TeamSummary
+		if err := rows.Scan(&team.TeamName, &team.ParentTeam, &team.Members, &team.ActiveMembers, &team.OpenReviews); err != nil {
+			return nil, 0, err
+		}
+		teams = append(teams, &team)
+	}
+
+	return teams, total, rows.Err()
+}
+
+func (r *postgresRepository) RenameTeam(ctx context.Context, teamName, newName string) error {
+	tx, err := r.db(ctx).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var exists bool
+	err = tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM teams WHERE team_name = $1)", newName).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrTeamExists
+	}
+
+	before, err := snapshot(ctx, tx, teamTarget(teamName))
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(ctx, "UPDATE teams SET team_name = $1 WHERE team_name = $2", newName, teamName)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrTeamNotFound
+	}
+
+	// Запись ведётся под прежним именем: после переименования по нему больше ничего не найти
+	after, err := snapshot(ctx, tx, teamTarget(newName))
+	if err != nil {
+		return err
+	}
+	if err := insertAudit(ctx, tx, "team.rename", teamTarget(teamName), before, after); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// DeleteTeam удаляет команду, переводя её участников в destinationTeam: для кого удаляемая команда
+// была основной, основной становится destinationTeam, остальные получают в ней дополнительное членство.
+// Удаление отклоняется, если у команды есть подкоманды (и reparentSubTeams не задан) или
+// открытые PR авторов, для которых она основная. Подкоманды при reparentSubTeams
+// переходят к родителю удаляемой команды.
+func (r *postgresRepository) DeleteTeam(ctx context.Context, teamName, destinationTeam string, reparentSubTeams bool) error {
+	tx, err := r.db(ctx).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var teamID int64
+	var parentID *int64
+	err = tx.QueryRow(ctx, `
+		SELECT team_id, parent_team_id FROM teams WHERE team_name = $1 FOR UPDATE
+	`, teamName).Scan(&teamID, &parentID)
+	if err == pgx.ErrNoRows {
+		return ErrTeamNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	before, err := snapshot(ctx, tx, teamTarget(teamName))
+	if err != nil {
+		return err
+	}
+
+	var hasOpenPRs bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM pull_requests pr
+			JOIN team_memberships tm ON tm.user_id = pr.author_id AND tm.is_primary
+			WHERE tm.team_id = $1 AND pr.status = 'OPEN'
+		)
+	`, teamID).Scan(&hasOpenPRs)
+	if err != nil {
+		return err
+	}
+	if hasOpenPRs {
+		return ErrTeamHasOpenPRs
+	}
+
+	if reparentSubTeams {
+		_, err = tx.Exec(ctx, "UPDATE teams SET parent_team_id = $1 WHERE parent_team_id = $2", parentID, teamID)
+	} else {
+		var hasSubTeams bool
+		err = tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM teams WHERE parent_team_id = $1)", teamID).Scan(&hasSubTeams)
+		if err == nil && hasSubTeams {
+			return ErrTeamHasSubTeams
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	var memberCount int
+	if err := tx.QueryRow(ctx, "SELECT COUNT(*) FROM team_memberships WHERE team_id = $1", teamID).Scan(&memberCount); err != nil {
+		return err
+	}
+	if memberCount > 0 {
+		if destinationTeam == "" {
+			return ErrDestinationRequired
+		}
+
+		var destinationID int64
+		err = tx.QueryRow(ctx, "SELECT team_id FROM teams WHERE team_name = $1", destinationTeam).Scan(&destinationID)
+		if err == pgx.ErrNoRows {
+			return ErrTeamNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		// Участники, для которых удаляемая команда основная, теряют прежнюю основную до вставки новой,
+		// чтобы не нарушить уникальность основной команды
+		_, err = tx.Exec(ctx, `
+			INSERT INTO team_memberships (user_id, team_id, is_primary, role)
+			SELECT user_id, $2, is_primary, role FROM team_memberships WHERE team_id = $1 AND NOT is_primary
+			ON CONFLICT (user_id, team_id) DO NOTHING
+		`, teamID, destinationID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `
+			WITH moved AS (
+				DELETE FROM team_memberships WHERE team_id = $1 AND is_primary RETURNING user_id, role
+			)
+			INSERT INTO team_memberships (user_id, team_id, is_primary, role)
+			SELECT user_id, $2, TRUE, role FROM moved
+			ON CONFLICT (user_id, team_id) DO UPDATE SET is_primary = TRUE
+		`, teamID, destinationID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM team_memberships WHERE team_id = $1", teamID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM teams WHERE team_id = $1", teamID); err != nil {
+		return err
+	}
+	if err := insertAudit(ctx, tx, "team.delete", teamTarget(teamName), before, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *postgresRepository) TeamExists(ctx context.Context, teamName string) (bool, error) {
+	var exists bool
+	err := r.db(ctx).QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM teams WHERE team_name = $1)", teamName).Scan(&exists)
+	return exists, err
+}
+
+// CreateUser создаёт пользователя (ErrUserExists, если такой уже есть) и добавляет его в команду user.TeamName
+// (основной, если другой основной команды у него нет)
+func (r *postgresRepository) CreateUser(ctx context.Context, user *model.User) error {
+	tx, err := r.db(ctx).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshot(ctx, tx, userTarget(user.UserID))
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO users (user_id, username, is_active) 
+		VALUES ($1, $2, $3)
+	`, user.UserID, user.Username, user.IsActive)
+	if isUniqueViolation(err) {
+		return ErrUserExists
+	}
+	if err != nil {
+		return err
+	}
+
+	if user.TeamName != "" {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO team_memberships (user_id, team_id, is_primary) 
+			SELECT $1, team_id, NOT EXISTS(SELECT 1 FROM team_memberships WHERE user_id = $1 AND is_primary) 
+			FROM teams WHERE team_name = $2
+			ON CONFLICT (user_id, team_id) DO NOTHING
+		`, user.UserID, user.TeamName)
+		if err != nil {
+			return err
+		}
+	}
+	if err := writeAudit(ctx, tx, "user.create", userTarget(user.UserID), before); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// userColumns — поля пользователя вместе с основной командой и списком всех команд
+const userColumns = `
+	u.user_id, u.username, u.is_active,
+	COALESCE((
+		SELECT t.team_name FROM team_memberships tm JOIN teams t ON t.team_id = tm.team_id 
+		WHERE tm.user_id = u.user_id AND tm.is_primary
+	), ''),
+	ARRAY(
+		SELECT t.team_name FROM team_memberships tm JOIN teams t ON t.team_id = tm.team_id 
+		WHERE tm.user_id = u.user_id 
+		ORDER BY tm.is_primary DESC, t.team_name
+	),
+	u.absent_from, u.absent_until`
+
+func (r *postgresRepository) GetUser(ctx context.Context, userID string) (*model.User, error) {
+	var user model.User
+	err := r.db(ctx).QueryRow(ctx, `
+		SELECT `+userColumns+`
+		FROM users u 
+		WHERE u.user_id = $1
+	`, userID).Scan(&user.UserID, &user.Username, &user.IsActive, &user.TeamName, &user.Teams, &user.AbsentFrom, &user.AbsentUntil)
+	
+	if err == pgx.ErrNoRows {
+		return nil, ErrUserNotFound
+	}
+	return &user, err
+}
+
+// SetUserAbsence задаёт период отсутствия; until == nil снимает отсутствие
+func (r *postgresRepository) SetUserAbsence(ctx context.Context, userID string, from, until *time.Time) (*model.User, error) {
+	err := r.audited(ctx, "user.set_absence", userTarget(userID), func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, `
+			UPDATE users 
+			SET absent_from = $1, absent_until = $2, updated_at = NOW() 
+			WHERE user_id = $3
+		`, from, until, userID)
+		if err != nil {
+			return err
+		}
+		if result.RowsAffected() == 0 {
+			return ErrUserNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetUser(ctx, userID)
+}
+
+func (r *postgresRepository) SetUserActive(ctx context.Context, userID string, isActive bool) (*model.User, error) {
+	err := r.audited(ctx, "user.set_active", userTarget(userID), func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, `
+			UPDATE users 
+			SET is_active = $1, updated_at = NOW() 
+			WHERE user_id = $2
+		`, isActive, userID)
+		if err != nil {
+			return err
+		}
+		if result.RowsAffected() == 0 {
+			return ErrUserNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetUser(ctx, userID)
+}
+
+// ListUsers возвращает страницу пользователей, упорядоченных по user_id, и общее число подходящих под фильтр
+func (r *postgresRepository) ListUsers(ctx context.Context, filter model.UserFilter) ([]*model.User, int, error) {
+	const where = `
+		WHERE ($1 = '' OR EXISTS(
+				SELECT 1 FROM team_memberships tm JOIN teams t ON t.team_id = tm.team_id 
+				WHERE tm.user_id = u.user_id AND t.team_name = $1
+			))
+			AND ($2::boolean IS NULL OR u.is_active = $2)
+			AND ($3 = '' OR starts_with(lower(u.username), lower($3)))
+	`
+
+	var total int
+	err := r.db(ctx).QueryRow(ctx, "SELECT COUNT(*) FROM users u"+where,
+		filter.TeamName, filter.IsActive, filter.NamePrefix).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db(ctx).Query(ctx, "SELECT "+userColumns+" FROM users u"+where+"ORDER BY u.user_id LIMIT $4 OFFSET $5",
+		filter.TeamName, filter.IsActive, filter.NamePrefix, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	users := []*model.User{}
+	for rows.Next() {
+		var user model.User
+		if err := rows.Scan(&user.UserID, &user.Username, &user.IsActive, &user.TeamName, &user.Teams, &user.AbsentFrom, &user.AbsentUntil); err != nil {
+			return nil, 0, err
+		}
+		users = append(users, &user)
+	}
+
+	return users, total, rows.Err()
+}
+
+func (r *postgresRepository) UpdateUser(ctx context.Context, update *model.UserUpdate) (*model.User, error) {
+	err := r.audited(ctx, "user.update", userTarget(update.UserID), func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, `
+			UPDATE users 
+			SET username = COALESCE($1, username), is_active = COALESCE($2, is_active), updated_at = NOW() 
+			WHERE user_id = $3
+		`, update.Username, update.IsActive, update.UserID)
+		if err != nil {
+			return err
+		}
+		if result.RowsAffected() == 0 {
+			return ErrUserNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetUser(ctx, update.UserID)
+}
+
+// GetActiveUsersByTeam возвращает активных участников команды, включая тех, для кого она не основная
+func (r *postgresRepository) GetActiveUsersByTeam(ctx context.Context, teamName string, excludeUserID string) ([]*model.User, error) {
+	rows, err := r.db(ctx).Query(ctx, `
+		SELECT u.user_id, u.username, u.is_active,
+			COALESCE((
+				SELECT pt.team_name FROM team_memberships ptm JOIN teams pt ON pt.team_id = ptm.team_id 
+				WHERE ptm.user_id = u.user_id AND ptm.is_primary
+			), '')
+		FROM team_memberships tm
+		JOIN teams t ON t.team_id = tm.team_id
+		JOIN users u ON u.user_id = tm.user_id
+		WHERE t.team_name = $1 AND u.is_active = true AND u.user_id != $2
+	`, teamName, excludeUserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*model.User
+	for rows.Next() {
+		var user model.User
+		if err := rows.Scan(&user.UserID, &user.Username, &user.IsActive, &user.TeamName); err != nil {
+			return nil, err
+		}
+		users = append(users, &user)
+	}
+
+	return users, nil
+}
+
+func (r *postgresRepository) UserExists(ctx context.Context, userID string) (bool, error) {
+	var exists bool
+	err := r.db(ctx).QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM users WHERE user_id = $1)", userID).Scan(&exists)
+	return exists, err
+}
+
+func (r *postgresRepository) CreatePullRequest(ctx context.Context, pr *model.PullRequest, reviewers []string) error {
+	tx, err := r.db(ctx).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status) 
+		VALUES ($1, $2, $3, $4)
+	`, pr.PullRequestID, pr.PullRequestName, pr.AuthorID, "OPEN")
+	if err != nil {
+		return ErrPRExists
+	}
+	err = recordPREvent(ctx, tx, pr.PullRequestID, model.PullRequestHistoryEvent{Type: model.PRHistoryCreated, UserID: pr.AuthorID})
+	if err != nil {
+		return err
+	}
+
+	for _, reviewerID := range reviewers {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO pr_reviewers (pull_request_id, user_id) 
+			VALUES ($1, $2)
+		`, pr.PullRequestID, reviewerID)
+		if err == nil {
+			err = recordPREvent(ctx, tx, pr.PullRequestID, model.PullRequestHistoryEvent{Type: model.PRHistoryAssigned, UserID: reviewerID})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	for _, shadowID := range pr.ShadowReviewers {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO pr_reviewers (pull_request_id, user_id, is_shadow) 
+			VALUES ($1, $2, TRUE)
+		`, pr.PullRequestID, shadowID)
+		if err == nil {
+			err = recordPREvent(ctx, tx, pr.PullRequestID, model.PullRequestHistoryEvent{
+				Type: model.PRHistoryAssigned, UserID: shadowID, IsShadow: true,
+			})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := writeAudit(ctx, tx, "pull_request.create", prTarget(pr.PullRequestID), nil); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *postgresRepository) GetPullRequest(ctx context.Context, prID string) (*model.PullRequest, error) {
+	var pr model.PullRequest
+	err := r.db(ctx).QueryRow(ctx, `
+		SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at
+		FROM pull_requests 
+		WHERE pull_request_id = $1
+	`, prID).Scan(
+		&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status, 
+		&pr.CreatedAt, &pr.MergedAt,
+	)
+	
+	if err == pgx.ErrNoRows {
+		return nil, ErrPRNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db(ctx).Query(ctx, `
+		SELECT user_id, is_shadow, COALESCE(verdict, '') FROM pr_reviewers WHERE pull_request_id = $1
+	`, prID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var reviewerID, verdict string
+		var isShadow bool
+		if err := rows.Scan(&reviewerID, &isShadow, &verdict); err != nil {
+			return nil, err
+		}
+		if isShadow {
+			pr.ShadowReviewers = append(pr.ShadowReviewers, reviewerID)
+		} else {
+			pr.AssignedReviewers = append(pr.AssignedReviewers, reviewerID)
+		}
+		if verdict != "" {
+			if pr.Verdicts == nil {
+				pr.Verdicts = map[string]string{}
+			}
+			pr.Verdicts[reviewerID] = verdict
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	pr.QuorumReached = pr.HasQuorum()
+
+	return &pr, nil
+}
+
+// SubmitVerdict записывает вердикт ревьюера userID (основного или теневого) по открытому PR;
+// повторный вердикт заменяет прежний
+func (r *postgresRepository) SubmitVerdict(ctx context.Context, prID, userID, verdict string) error {
+	tx, err := r.db(ctx).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	err = tx.QueryRow(ctx, "SELECT status FROM pull_requests WHERE pull_request_id = $1 FOR UPDATE", prID).Scan(&status)
+	if err == pgx.ErrNoRows {
+		return ErrPRNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if status == "MERGED" {
+		return ErrPRMerged
+	}
+	if status == "CLOSED" {
+		return ErrPRClosed
+	}
+
+	before, err := snapshot(ctx, tx, prTarget(prID))
+	if err != nil {
+		return err
+	}
+
+	var isShadow bool
+	err = tx.QueryRow(ctx, `
+		UPDATE pr_reviewers 
+		SET verdict = $3, verdict_at = NOW() 
+		WHERE pull_request_id = $1 AND user_id = $2
+		RETURNING is_shadow
+	`, prID, userID, verdict).Scan(&isShadow)
+	if err == pgx.ErrNoRows {
+		return ErrUserNotAssigned
+	}
+	if err != nil {
+		return err
+	}
+	err = recordPREvent(ctx, tx, prID, model.PullRequestHistoryEvent{
+		Type: model.PRHistoryVerdict, UserID: userID, IsShadow: isShadow, Verdict: verdict,
+	})
+	if err != nil {
+		return err
+	}
+	if err := writeAudit(ctx, tx, "pull_request.verdict", prTarget(prID), before); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *postgresRepository) MergePullRequest(ctx context.Context, prID string) error {
+	tx, err := r.db(ctx).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshot(ctx, tx, prTarget(prID))
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return ErrPRNotFound
+	}
+
+	result, err := tx.Exec(ctx, `
+		UPDATE pull_requests 
+		SET status = 'MERGED', merged_at = NOW(), updated_at = NOW()
+		WHERE pull_request_id = $1 AND status = 'OPEN'
+	`, prID)
+	if err != nil {
+		return err
+	}
+	// Повторный merge ничего не меняет и в журнал не попадает
+	if result.RowsAffected() == 0 {
+		return nil
+	}
+	if err := recordPREvent(ctx, tx, prID, model.PullRequestHistoryEvent{Type: model.PRHistoryMerged}); err != nil {
+		return err
+	}
+	if err := writeAudit(ctx, tx, "pull_request.merge", prTarget(prID), before); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *postgresRepository) ClosePullRequest(ctx context.Context, prID string) error {
+	tx, err := r.db(ctx).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshot(ctx, tx, prTarget(prID))
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(ctx, `
+		UPDATE pull_requests 
+		SET status = 'CLOSED', closed_at = NOW(), updated_at = NOW()
+		WHERE pull_request_id = $1 AND status = 'OPEN'
+	`, prID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		status, err := r.getPullRequestStatus(ctx, prID)
+		if err != nil {
+			return err
+		}
+		if status == "MERGED" {
+			return ErrPRMerged
+		}
+		return nil
+	}
+	if err := recordPREvent(ctx, tx, prID, model.PullRequestHistoryEvent{Type: model.PRHistoryClosed}); err != nil {
+		return err
+	}
+	if err := writeAudit(ctx, tx, "pull_request.close", prTarget(prID), before); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *postgresRepository) ReopenPullRequest(ctx context.Context, prID string) error {
+	tx, err := r.db(ctx).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshot(ctx, tx, prTarget(prID))
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(ctx, `
+		UPDATE pull_requests 
+		SET status = 'OPEN', closed_at = NULL, updated_at = NOW()
+		WHERE pull_request_id = $1 AND status = 'CLOSED'
+	`, prID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		status, err := r.getPullRequestStatus(ctx, prID)
+		if err != nil {
+			return err
+		}
+		if status == "MERGED" {
+			return ErrPRMerged
+		}
+		return nil
+	}
+	if err := recordPREvent(ctx, tx, prID, model.PullRequestHistoryEvent{Type: model.PRHistoryReopened}); err != nil {
+		return err
+	}
+	if err := writeAudit(ctx, tx, "pull_request.reopen", prTarget(prID), before); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *postgresRepository) getPullRequestStatus(ctx context.Context, prID string) (string, error) {
+	var status string
+	err := r.db(ctx).QueryRow(ctx, "SELECT status FROM pull_requests WHERE pull_request_id = $1", prID).Scan(&status)
+	if err == pgx.ErrNoRows {
+		return "", ErrPRNotFound
+	}
+	return status, err
+}
+
+func (r *postgresRepository) ReassignReviewer(ctx context.Context, prID string, oldUserID string, newUserID string) error {
+	tx, err := r.db(ctx).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	err = tx.QueryRow(ctx, "SELECT status FROM pull_requests WHERE pull_request_id = $1", prID).Scan(&status)
+	if err == pgx.ErrNoRows {
+		return ErrPRNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if status == "MERGED" {
+		return ErrPRMerged
+	}
+	if status == "CLOSED" {
+		return ErrPRClosed
+	}
+
+	var assigned bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM pr_reviewers WHERE pull_request_id = $1 AND user_id = $2 AND NOT is_shadow)
+	`, prID, oldUserID).Scan(&assigned)
+	if err != nil {
+		return err
+	}
+	if !assigned {
+		return ErrUserNotAssigned
+	}
+
+	before, err := snapshot(ctx, tx, prTarget(prID))
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE pr_reviewers 
+		SET user_id = $1, assigned_at = NOW(), verdict = NULL, verdict_at = NULL
+		WHERE pull_request_id = $2 AND user_id = $3
+	`, newUserID, prID, oldUserID)
+	if err != nil {
+		return err
+	}
+	if err := recordReassignment(ctx, tx, prID, oldUserID, newUserID, "manual", false); err != nil {
+		return err
+	}
+	if err := writeAudit(ctx, tx, "pull_request.reassign", prTarget(prID), before); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// recordReassignment пишет переназначение в журнал и историю PR; пустой newUserID означает снятие без замены.
+// isShadow — был ли заменяемый ревьюер теневым: замена наследует его роль.
+func recordReassignment(ctx context.Context, tx pgx.Tx, prID, oldUserID, newUserID, reason string, isShadow bool) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO reviewer_reassignments (pull_request_id, old_user_id, new_user_id, reason) 
+		VALUES ($1, $2, NULLIF($3, ''), $4)
+	`, prID, oldUserID, newUserID, reason)
+	if err != nil {
+		return err
+	}
+
+	event := model.PullRequestHistoryEvent{
+		Type: model.PRHistoryReassigned, UserID: newUserID, OldUserID: oldUserID, IsShadow: isShadow, Reason: reason,
+	}
+	if newUserID == "" {
+		event = model.PullRequestHistoryEvent{Type: model.PRHistoryUnassigned, UserID: oldUserID, IsShadow: isShadow, Reason: reason}
+	}
+	return recordPREvent(ctx, tx, prID, event)
+}
+
+// AddShadowReviewer назначает теневого ревьюера на открытый PR
+func (r *postgresRepository) AddShadowReviewer(ctx context.Context, prID, userID string) error {
+	tx, err := r.db(ctx).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	err = tx.QueryRow(ctx, "SELECT status FROM pull_requests WHERE pull_request_id = $1 FOR UPDATE", prID).Scan(&status)
+	if err == pgx.ErrNoRows {
+		return ErrPRNotFound
+	}
 	if err != nil {
 		return err
 	}
-	defer tx.Rollback(ctx)
+	if status == "MERGED" {
+		return ErrPRMerged
+	}
+	if status == "CLOSED" {
+		return ErrPRClosed
+	}
 
-	_, err = tx.Exec(ctx, "INSERT INTO teams (team_name) VALUES ($1)", team.TeamName)
+	before, err := snapshot(ctx, tx, prTarget(prID))
 	if err != nil {
-		return ErrTeamExists
+		return err
 	}
 
-	for _, member := range team.Members {
-		_, err = tx.Exec(ctx, `
-			INSERT INTO users (user_id, username, team_name, is_active) 
-			VALUES ($1, $2, $3, $4)
-			ON CONFLICT (user_id) DO UPDATE SET
-				username = EXCLUDED.username,
-				team_name = EXCLUDED.team_name,
-				is_active = EXCLUDED.is_active,
-				updated_at = NOW()
-		`, member.UserID, member.Username, team.TeamName, member.IsActive)
-		if err != nil {
-			return err
-		}
+	result, err := tx.Exec(ctx, `
+		INSERT INTO pr_reviewers (pull_request_id, user_id, is_shadow) 
+		VALUES ($1, $2, TRUE)
+		ON CONFLICT (pull_request_id, user_id) DO NOTHING
+	`, prID, userID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrAlreadyReviewer
+	}
+	err = recordPREvent(ctx, tx, prID, model.PullRequestHistoryEvent{Type: model.PRHistoryAssigned, UserID: userID, IsShadow: true})
+	if err != nil {
+		return err
+	}
+	if err := writeAudit(ctx, tx, "pull_request.add_shadow", prTarget(prID), before); err != nil {
+		return err
 	}
 
 	return tx.Commit(ctx)
 }
 
-func (r *postgresRepository) GetTeam(ctx context.Context, teamName string) (*model.Team, error) {
-	var team model.Team
-	team.TeamName = teamName
+func (r *postgresRepository) RemoveShadowReviewer(ctx context.Context, prID, userID string) error {
+	return r.audited(ctx, "pull_request.remove_shadow", prTarget(prID), func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, `
+			DELETE FROM pr_reviewers 
+			WHERE pull_request_id = $1 AND user_id = $2 AND is_shadow
+		`, prID, userID)
+		if err != nil {
+			return err
+		}
+		if result.RowsAffected() == 0 {
+			return ErrUserNotAssigned
+		}
+		return recordPREvent(ctx, tx, prID, model.PullRequestHistoryEvent{Type: model.PRHistoryUnassigned, UserID: userID, IsShadow: true})
+	})
+}
 
-	rows, err := r.pool.Query(ctx, `
-		SELECT user_id, username, is_active 
-		FROM users 
-		WHERE team_name = $1
-	`, teamName)
+func (r *postgresRepository) GetUserReviewRequests(ctx context.Context, userID string) ([]*model.PullRequestShort, error) {
+	rows, err := r.db(ctx).Query(ctx, `
+		SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status
+		FROM pull_requests pr
+		JOIN pr_reviewers prr ON pr.pull_request_id = prr.pull_request_id
+		WHERE prr.user_id = $1
+		ORDER BY pr.created_at DESC
+	`, userID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	var prs []*model.PullRequestShort
 	for rows.Next() {
-		var member model.TeamMember
-		if err := rows.Scan(&member.UserID, &member.Username, &member.IsActive); err != nil {
+		var pr model.PullRequestShort
+		if err := rows.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status); err != nil {
 			return nil, err
 		}
-		team.Members = append(team.Members, member)
-	}
-
-	if len(team.Members) == 0 {
-		return nil, ErrTeamNotFound
+		prs = append(prs, &pr)
 	}
 
-	return &team, nil
+	return prs, nil
 }
 
-func (r *postgresRepository) TeamExists(ctx context.Context, teamName string) (bool, error) {
+func (r *postgresRepository) PRExists(ctx context.Context, prID string) (bool, error) {
 	var exists bool
-	err := r.pool.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM teams WHERE team_name = $1)", teamName).Scan(&exists)
+	err := r.db(ctx).QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM pull_requests WHERE pull_request_id = $1)", prID).Scan(&exists)
 	return exists, err
 }
 
-func (r *postgresRepository) CreateUser(ctx context.Context, user *model.User) error {
-	_, err := r.pool.Exec(ctx, `
-		INSERT INTO users (user_id, username, team_name, is_active) 
-		VALUES ($1, $2, $3, $4)
-		ON CONFLICT (user_id) DO UPDATE SET
-			username = EXCLUDED.username,
-			team_name = EXCLUDED.team_name,
-			is_active = EXCLUDED.is_active,
-			updated_at = NOW()
-	`, user.UserID, user.Username, user.TeamName, user.IsActive)
-	return err
+func (r *postgresRepository) IsUserAssignedToPR(ctx context.Context, prID string, userID string) (bool, error) {
+	var assigned bool
+	err := r.db(ctx).QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM pr_reviewers 
+			WHERE pull_request_id = $1 AND user_id = $2 AND NOT is_shadow
+		)
+	`, prID, userID).Scan(&assigned)
+	return assigned, err
 }
 
-func (r *postgresRepository) GetUser(ctx context.Context, userID string) (*model.User, error) {
-	var user model.User
-	err := r.pool.QueryRow(ctx, `
-		SELECT user_id, username, team_name, is_active 
-		FROM users 
-		WHERE user_id = $1
-	`, userID).Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive)
-	
+func (r *postgresRepository) SetUserIdentity(ctx context.Context, identity *model.UserIdentity) error {
+	return r.audited(ctx, "identity.link", identitiesTarget(identity.UserID), func(tx pgx.Tx) error {
+		// Числовой id переходит к новому логину: после переименования аккаунта старая привязка его теряет
+		_, err := tx.Exec(ctx, `
+			UPDATE user_identities SET external_id = NULL 
+			WHERE provider = $1 AND external_id = $2 AND external_login <> $3
+		`, identity.Provider, identity.ExternalID, identity.ExternalLogin)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO user_identities (provider, external_login, external_id, user_id) 
+			VALUES ($1, $2, NULLIF($3, ''), $4)
+			ON CONFLICT (provider, external_login) DO UPDATE SET
+				user_id = EXCLUDED.user_id,
+				external_id = COALESCE(EXCLUDED.external_id, user_identities.external_id)
+		`, identity.Provider, identity.ExternalLogin, identity.ExternalID, identity.UserID)
+		return err
+	})
+}
+
+func (r *postgresRepository) GetUserIDByExternalID(ctx context.Context, provider, externalID string) (string, error) {
+	var userID string
+	err := r.db(ctx).QueryRow(ctx, `
+		SELECT user_id FROM user_identities WHERE provider = $1 AND external_id = $2
+	`, provider, externalID).Scan(&userID)
+
 	if err == pgx.ErrNoRows {
-		return nil, ErrUserNotFound
+		return "", ErrIdentityNotFound
 	}
-	return &user, err
+	return userID, err
 }
 
-func (r *postgresRepository) SetUserActive(ctx context.Context, userID string, isActive bool) (*model.User, error) {
-	var user model.User
-	err := r.pool.QueryRow(ctx, `
-		UPDATE users 
-		SET is_active = $1, updated_at = NOW() 
-		WHERE user_id = $2 
-		RETURNING user_id, username, team_name, is_active
-	`, isActive, userID).Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive)
-	
+func (r *postgresRepository) GetUserIDByIdentity(ctx context.Context, provider, externalLogin string) (string, error) {
+	var userID string
+	err := r.db(ctx).QueryRow(ctx, `
+		SELECT user_id 
+		FROM user_identities 
+		WHERE provider = $1 AND external_login = $2
+	`, provider, externalLogin).Scan(&userID)
+
 	if err == pgx.ErrNoRows {
-		return nil, ErrUserNotFound
+		return "", ErrIdentityNotFound
 	}
-	return &user, err
+	return userID, err
 }
 
-func (r *postgresRepository) GetActiveUsersByTeam(ctx context.Context, teamName string, excludeUserID string) ([]*model.User, error) {
-	rows, err := r.pool.Query(ctx, `
-		SELECT user_id, username, team_name, is_active 
-		FROM users 
-		WHERE team_name = $1 AND is_active = true AND user_id != $2
-	`, teamName, excludeUserID)
+// RegisterWebhookDelivery возвращает false, если доставка с таким id уже была обработана
+func (r *postgresRepository) RegisterWebhookDelivery(ctx context.Context, provider, deliveryID string) (bool, error) {
+	result, err := r.db(ctx).Exec(ctx, `
+		INSERT INTO webhook_deliveries (provider, delivery_id) 
+		VALUES ($1, $2)
+		ON CONFLICT (provider, delivery_id) DO NOTHING
+	`, provider, deliveryID)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+func (r *postgresRepository) GetIdentityLogins(ctx context.Context, provider string, userIDs []string) ([]string, error) {
+	rows, err := r.db(ctx).Query(ctx, `
+		SELECT external_login 
+		FROM user_identities 
+		WHERE provider = $1 AND user_id = ANY($2)
+	`, provider, userIDs)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var users []*model.User
+	var logins []string
 	for rows.Next() {
-		var user model.User
-		if err := rows.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive); err != nil {
+		var login string
+		if err := rows.Scan(&login); err != nil {
 			return nil, err
 		}
-		users = append(users, &user)
+		logins = append(logins, login)
 	}
 
-	return users, nil
+	return logins, rows.Err()
 }
 
-func (r *postgresRepository) UserExists(ctx context.Context, userID string) (bool, error) {
-	var exists bool
-	err := r.pool.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM users WHERE user_id = $1)", userID).Scan(&exists)
-	return exists, err
+func (r *postgresRepository) SetPullRequestLink(ctx context.Context, link *model.PullRequestLink) error {
+	return r.audited(ctx, "pull_request.link", prTarget(link.PullRequestID), func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO pull_request_links (pull_request_id, provider, repository, number) 
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (pull_request_id) DO NOTHING
+		`, link.PullRequestID, link.Provider, link.Repository, link.Number)
+		return err
+	})
 }
 
-func (r *postgresRepository) CreatePullRequest(ctx context.Context, pr *model.PullRequest, reviewers []string) error {
-	tx, err := r.pool.Begin(ctx)
-	if err != nil {
-		return err
+func (r *postgresRepository) GetPullRequestLink(ctx context.Context, prID string) (*model.PullRequestLink, error) {
+	var link model.PullRequestLink
+	err := r.db(ctx).QueryRow(ctx, `
+		SELECT pull_request_id, provider, repository, number 
+		FROM pull_request_links 
+		WHERE pull_request_id = $1
+	`, prID).Scan(&link.PullRequestID, &link.Provider, &link.Repository, &link.Number)
+
+	if err == pgx.ErrNoRows {
+		return nil, ErrPRLinkNotFound
 	}
-	defer tx.Rollback(ctx)
+	return &link, err
+}
 
-	_, err = tx.Exec(ctx, `
-		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status) 
-		VALUES ($1, $2, $3, $4)
-	`, pr.PullRequestID, pr.PullRequestName, pr.AuthorID, "OPEN")
+func (r *postgresRepository) EnqueueReviewerSync(ctx context.Context, prID string, action string, userIDs []string) error {
+	_, err := r.db(ctx).Exec(ctx, `
+		INSERT INTO reviewer_sync_jobs (pull_request_id, action, user_ids) 
+		VALUES ($1, $2, $3)
+	`, prID, action, userIDs)
+	return err
+}
+
+// ClaimReviewerSyncJobs забирает готовые к отправке задания и откладывает их на время lease,
+// чтобы параллельные воркеры не взяли то же задание. Задания, исчерпавшие maxAttempts,
+// остаются в таблице с last_error и больше не выбираются.
+func (r *postgresRepository) ClaimReviewerSyncJobs(ctx context.Context, limit, maxAttempts int, lease time.Duration) ([]*model.ReviewerSyncJob, error) {
+	rows, err := r.db(ctx).Query(ctx, `
+		UPDATE reviewer_sync_jobs 
+		SET attempts = attempts + 1, next_attempt_at = NOW() + $2 * INTERVAL '1 second'
+		WHERE id IN (
+			SELECT id FROM reviewer_sync_jobs 
+			WHERE completed_at IS NULL AND next_attempt_at <= NOW() AND attempts < $3
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, pull_request_id, action, user_ids, attempts
+	`, limit, lease.Seconds(), maxAttempts)
 	if err != nil {
-		return ErrPRExists
+		return nil, err
 	}
+	defer rows.Close()
 
-	for _, reviewerID := range reviewers {
-		_, err = tx.Exec(ctx, `
-			INSERT INTO pr_reviewers (pull_request_id, user_id) 
-			VALUES ($1, $2)
-		`, pr.PullRequestID, reviewerID)
-		if err != nil {
-			return err
+	var jobs []*model.ReviewerSyncJob
+	for rows.Next() {
+		var job model.ReviewerSyncJob
+		if err := rows.Scan(&job.ID, &job.PullRequestID, &job.Action, &job.UserIDs, &job.Attempts); err != nil {
+			return nil, err
 		}
+		jobs = append(jobs, &job)
 	}
 
-	return tx.Commit(ctx)
+	return jobs, rows.Err()
 }
 
-func (r *postgresRepository) GetPullRequest(ctx context.Context, prID string) (*model.PullRequest, error) {
-	var pr model.PullRequest
-	err := r.pool.QueryRow(ctx, `
-		SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at
-		FROM pull_requests 
-		WHERE pull_request_id = $1
-	`, prID).Scan(
-		&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status, 
-		&pr.CreatedAt, &pr.MergedAt,
-	)
-	
-	if err == pgx.ErrNoRows {
-		return nil, ErrPRNotFound
-	}
+func (r *postgresRepository) CompleteReviewerSyncJob(ctx context.Context, jobID int64) error {
+	_, err := r.db(ctx).Exec(ctx, `
+		UPDATE reviewer_sync_jobs 
+		SET completed_at = NOW(), last_error = NULL 
+		WHERE id = $1
+	`, jobID)
+	return err
+}
+
+func (r *postgresRepository) FailReviewerSyncJob(ctx context.Context, jobID int64, cause string, retryAt time.Time) error {
+	_, err := r.db(ctx).Exec(ctx, `
+		UPDATE reviewer_sync_jobs 
+		SET last_error = $2, next_attempt_at = $3 
+		WHERE id = $1
+	`, jobID, cause, retryAt)
+	return err
+}
+
+func (r *postgresRepository) SetNotificationPreference(ctx context.Context, pref *model.NotificationPreference) error {
+	return r.audited(ctx, "notifications.set", notificationsTarget(pref.UserID), func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO notification_preferences (user_id, channel, target, on_assigned, on_reassigned, on_merged, daily_digest) 
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (user_id, channel) DO UPDATE SET
+				target = EXCLUDED.target,
+				on_assigned = EXCLUDED.on_assigned,
+				on_reassigned = EXCLUDED.on_reassigned,
+				on_merged = EXCLUDED.on_merged,
+				daily_digest = EXCLUDED.daily_digest,
+				updated_at = NOW()
+		`, pref.UserID, pref.Channel, pref.Target, pref.OnAssigned, pref.OnReassigned, pref.OnMerged, pref.DailyDigest)
+		return err
+	})
+}
+
+func (r *postgresRepository) DeleteNotificationPreference(ctx context.Context, userID, channel string) error {
+	return r.audited(ctx, "notifications.delete", notificationsTarget(userID), func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, "DELETE FROM notification_preferences WHERE user_id = $1 AND channel = $2", userID, channel)
+		return err
+	})
+}
+
+func (r *postgresRepository) GetNotificationPreferences(ctx context.Context, userIDs []string) ([]*model.NotificationPreference, error) {
+	rows, err := r.db(ctx).Query(ctx, `
+		SELECT user_id, channel, target, on_assigned, on_reassigned, on_merged, daily_digest 
+		FROM notification_preferences 
+		WHERE user_id = ANY($1)
+		ORDER BY user_id, channel
+	`, userIDs)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	rows, err := r.pool.Query(ctx, `
-		SELECT user_id FROM pr_reviewers WHERE pull_request_id = $1
-	`, prID)
+	return scanNotificationPreferences(rows)
+}
+
+func (r *postgresRepository) GetDigestSubscribers(ctx context.Context, channel string) ([]*model.NotificationPreference, error) {
+	rows, err := r.db(ctx).Query(ctx, `
+		SELECT np.user_id, np.channel, np.target, np.on_assigned, np.on_reassigned, np.on_merged, np.daily_digest 
+		FROM notification_preferences np
+		JOIN users u ON u.user_id = np.user_id
+		WHERE np.channel = $1 AND np.daily_digest = true AND u.is_active = true
+		ORDER BY np.user_id
+	`, channel)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	return scanNotificationPreferences(rows)
+}
+
+func scanNotificationPreferences(rows pgx.Rows) ([]*model.NotificationPreference, error) {
+	var prefs []*model.NotificationPreference
 	for rows.Next() {
-		var reviewerID string
-		if err := rows.Scan(&reviewerID); err != nil {
+		var pref model.NotificationPreference
+		if err := rows.Scan(&pref.UserID, &pref.Channel, &pref.Target, &pref.OnAssigned, &pref.OnReassigned, &pref.OnMerged, &pref.DailyDigest); err != nil {
 			return nil, err
 		}
-		pr.AssignedReviewers = append(pr.AssignedReviewers, reviewerID)
+		prefs = append(prefs, &pref)
 	}
 
-	return &pr, nil
+	return prefs, rows.Err()
 }
 
-func (r *postgresRepository) MergePullRequest(ctx context.Context, prID string) error {
-	result, err := r.pool.Exec(ctx, `
-		UPDATE pull_requests 
-		SET status = 'MERGED', merged_at = NOW(), updated_at = NOW()
-		WHERE pull_request_id = $1 AND status = 'OPEN'
-	`, prID)
-	
+// GetReviewerStats считает статистику по ревьюерам, у которых за период были назначения или переназначения.
+// filter.TeamName ограничивает выборку участниками команды.
+func (r *postgresRepository) GetReviewerStats(ctx context.Context, filter model.StatsFilter) ([]*model.ReviewerStats, error) {
+	rows, err := r.db(ctx).Query(ctx, `
+		SELECT u.user_id, u.username,
+			COALESCE(a.assigned, 0), COALESCE(a.open, 0), COALESCE(a.merged, 0),
+			COALESCE(moved_in.count, 0), COALESCE(moved_out.count, 0), a.avg_hours
+		FROM users u
+		LEFT JOIN (
+			SELECT prr.user_id, 
+				COUNT(*) AS assigned,
+				COUNT(*) FILTER (WHERE pr.status = 'OPEN') AS open,
+				COUNT(*) FILTER (WHERE pr.status = 'MERGED') AS merged,
+				AVG(EXTRACT(EPOCH FROM pr.merged_at - pr.created_at) / 3600) FILTER (WHERE pr.status = 'MERGED') AS avg_hours
+			FROM pr_reviewers prr
+			JOIN pull_requests pr ON pr.pull_request_id = prr.pull_request_id
+			WHERE NOT prr.is_shadow 
+				AND ($1::timestamptz IS NULL OR prr.assigned_at >= $1) 
+				AND ($2::timestamptz IS NULL OR prr.assigned_at < $2)
+			GROUP BY prr.user_id
+		) a ON a.user_id = u.user_id
+		LEFT JOIN (
+			SELECT new_user_id AS user_id, COUNT(*) AS count 
+			FROM reviewer_reassignments
+			WHERE new_user_id IS NOT NULL 
+				AND ($1::timestamptz IS NULL OR reassigned_at >= $1) 
+				AND ($2::timestamptz IS NULL OR reassigned_at < $2)
+			GROUP BY new_user_id
+		) moved_in ON moved_in.user_id = u.user_id
+		LEFT JOIN (
+			SELECT old_user_id AS user_id, COUNT(*) AS count 
+			FROM reviewer_reassignments
+			WHERE ($1::timestamptz IS NULL OR reassigned_at >= $1) 
+				AND ($2::timestamptz IS NULL OR reassigned_at < $2)
+			GROUP BY old_user_id
+		) moved_out ON moved_out.user_id = u.user_id
+		WHERE (a.user_id IS NOT NULL OR moved_in.user_id IS NOT NULL OR moved_out.user_id IS NOT NULL)
+			AND ($3 = '' OR EXISTS(
+				SELECT 1 FROM team_memberships tm JOIN teams t ON t.team_id = tm.team_id 
+				WHERE tm.user_id = u.user_id AND t.team_name = $3
+			))
+		ORDER BY COALESCE(a.assigned, 0) DESC, u.user_id
+	`, filter.From, filter.To, filter.TeamName)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	
-	if result.RowsAffected() == 0 {
-		var exists bool
-		err := r.pool.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM pull_requests WHERE pull_request_id = $1)", prID).Scan(&exists)
+	defer rows.Close()
+
+	stats := []*model.ReviewerStats{}
+	for rows.Next() {
+		var s model.ReviewerStats
+		err := rows.Scan(&s.UserID, &s.Username, &s.Assigned, &s.Open, &s.Merged,
+			&s.ReassignedIn, &s.ReassignedOut, &s.AvgTimeToMergeHours)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		if !exists {
-			return ErrPRNotFound
+		stats = append(stats, &s)
+	}
+
+	return stats, rows.Err()
+}
+
+// GetTeamStats считает статистику по всем командам (или по одной, если задан filter.TeamName).
+// PR относятся к команде по текущей основной команде автора.
+func (r *postgresRepository) GetTeamStats(ctx context.Context, filter model.StatsFilter) ([]*model.TeamStats, error) {
+	rows, err := r.db(ctx).Query(ctx, `
+		SELECT t.team_name,
+			COALESCE(p.created, 0), COALESCE(p.open, 0), COALESCE(p.merged, 0),
+			COALESCE(a.assignments, 0), COALESCE(moved_in.count, 0), COALESCE(moved_out.count, 0), p.avg_hours
+		FROM teams t
+		LEFT JOIN (
+			SELECT tm.team_id, 
+				COUNT(*) AS created,
+				COUNT(*) FILTER (WHERE pr.status = 'OPEN') AS open,
+				COUNT(*) FILTER (WHERE pr.status = 'MERGED') AS merged,
+				AVG(EXTRACT(EPOCH FROM pr.merged_at - pr.created_at) / 3600) FILTER (WHERE pr.status = 'MERGED') AS avg_hours
+			FROM pull_requests pr
+			JOIN team_memberships tm ON tm.user_id = pr.author_id AND tm.is_primary
+			WHERE ($1::timestamptz IS NULL OR pr.created_at >= $1) 
+				AND ($2::timestamptz IS NULL OR pr.created_at < $2)
+			GROUP BY tm.team_id
+		) p ON p.team_id = t.team_id
+		LEFT JOIN (
+			SELECT tm.team_id, COUNT(*) AS assignments
+			FROM pr_reviewers prr
+			JOIN team_memberships tm ON tm.user_id = prr.user_id
+			WHERE NOT prr.is_shadow 
+				AND ($1::timestamptz IS NULL OR prr.assigned_at >= $1) 
+				AND ($2::timestamptz IS NULL OR prr.assigned_at < $2)
+			GROUP BY tm.team_id
+		) a ON a.team_id = t.team_id
+		LEFT JOIN (
+			SELECT tm.team_id, COUNT(*) AS count
+			FROM reviewer_reassignments rr
+			JOIN team_memberships tm ON tm.user_id = rr.new_user_id
+			WHERE ($1::timestamptz IS NULL OR rr.reassigned_at >= $1) 
+				AND ($2::timestamptz IS NULL OR rr.reassigned_at < $2)
+			GROUP BY tm.team_id
+		) moved_in ON moved_in.team_id = t.team_id
+		LEFT JOIN (
+			SELECT tm.team_id, COUNT(*) AS count
+			FROM reviewer_reassignments rr
+			JOIN team_memberships tm ON tm.user_id = rr.old_user_id
+			WHERE ($1::timestamptz IS NULL OR rr.reassigned_at >= $1) 
+				AND ($2::timestamptz IS NULL OR rr.reassigned_at < $2)
+			GROUP BY tm.team_id
+		) moved_out ON moved_out.team_id = t.team_id
+		WHERE $3 = '' OR t.team_name = $3
+		ORDER BY t.team_name
+	`, filter.From, filter.To, filter.TeamName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []*model.TeamStats{}
+	for rows.Next() {
+		var s model.TeamStats
+		err := rows.Scan(&s.TeamName, &s.PullRequests, &s.Open, &s.Merged,
+			&s.Assignments, &s.ReassignedIn, &s.ReassignedOut, &s.AvgTimeToMergeHours)
+		if err != nil {
+			return nil, err
 		}
+		stats = append(stats, &s)
 	}
-	
-	return nil
+
+	return stats, rows.Err()
 }
 
-func (r *postgresRepository) ReassignReviewer(ctx context.Context, prID string, oldUserID string, newUserID string) error {
-	tx, err := r.pool.Begin(ctx)
+// CountOpenReviewsByTeam считает открытые ревью участников каждой команды так же, как ListTeams
+func (r *postgresRepository) CountOpenReviewsByTeam(ctx context.Context) (map[string]int, error) {
+	rows, err := r.db(ctx).Query(ctx, `
+		SELECT t.team_name, COUNT(pr.pull_request_id)
+		FROM teams t
+		LEFT JOIN team_memberships tm ON tm.team_id = t.team_id
+		LEFT JOIN pr_reviewers prr ON prr.user_id = tm.user_id AND NOT prr.is_shadow
+		LEFT JOIN pull_requests pr ON pr.pull_request_id = prr.pull_request_id AND pr.status = 'OPEN'
+		GROUP BY t.team_name
+	`)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer tx.Rollback(ctx)
+	defer rows.Close()
 
-	var status string
-	err = tx.QueryRow(ctx, "SELECT status FROM pull_requests WHERE pull_request_id = $1", prID).Scan(&status)
-	if err == pgx.ErrNoRows {
-		return ErrPRNotFound
+	counts := map[string]int{}
+	for rows.Next() {
+		var teamName string
+		var count int
+		if err := rows.Scan(&teamName, &count); err != nil {
+			return nil, err
+		}
+		counts[teamName] = count
 	}
+
+	return counts, rows.Err()
+}
+
+const apiTokenColumns = `token_id, user_id, name, scopes, created_at, expires_at, last_used_at, revoked_at`
+
+func scanAPIToken(row pgx.Row) (*model.APIToken, error) {
+	var token model.APIToken
+	err := row.Scan(&token.TokenID, &token.UserID, &token.Name, &token.Scopes,
+		&token.CreatedAt, &token.ExpiresAt, &token.LastUsedAt, &token.RevokedAt)
 	if err != nil {
-		return err
+		if err == pgx.ErrNoRows {
+			return nil, ErrTokenNotFound
+		}
+		return nil, err
 	}
-	if status == "MERGED" {
-		return ErrPRMerged
+	return &token, nil
+}
+
+func (r *postgresRepository) CreateAPIToken(ctx context.Context, token *model.APIToken, tokenHash string) error {
+	tx, err := r.db(ctx).Begin(ctx)
+	if err != nil {
+		return err
 	}
+	defer tx.Rollback(ctx)
 
-	var assigned bool
-	err = tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM pr_reviewers WHERE pull_request_id = $1 AND user_id = $2)", prID, oldUserID).Scan(&assigned)
+	var exists bool
+	err = tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM users WHERE user_id = $1)`, token.UserID).Scan(&exists)
 	if err != nil {
 		return err
 	}
-	if !assigned {
-		return ErrUserNotAssigned
+	if !exists {
+		return ErrUserNotFound
 	}
 
-	_, err = tx.Exec(ctx, `
-		UPDATE pr_reviewers 
-		SET user_id = $1 
-		WHERE pull_request_id = $2 AND user_id = $3
-	`, newUserID, prID, oldUserID)
+	err = tx.QueryRow(ctx, `
+		INSERT INTO api_tokens (token_hash, user_id, name, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING token_id, created_at
+	`, tokenHash, token.UserID, token.Name, token.Scopes, token.ExpiresAt).Scan(&token.TokenID, &token.CreatedAt)
 	if err != nil {
 		return err
 	}
+	if err := writeAudit(ctx, tx, "api_token.issue", tokenTarget(token.TokenID), nil); err != nil {
+		return err
+	}
 
 	return tx.Commit(ctx)
 }
 
-func (r *postgresRepository) GetUserReviewRequests(ctx context.Context, userID string) ([]*model.PullRequestShort, error) {
-	rows, err := r.pool.Query(ctx, `
-		SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status
-		FROM pull_requests pr
-		JOIN pr_reviewers prr ON pr.pull_request_id = prr.pull_request_id
-		WHERE prr.user_id = $1
-		ORDER BY pr.created_at DESC
+// AuthenticateAPIToken находит действующий токен активного пользователя и отмечает его использование.
+// Отозванный, просроченный и неизвестный токены неотличимы: ErrTokenNotFound.
+func (r *postgresRepository) AuthenticateAPIToken(ctx context.Context, tokenHash string) (*model.APIToken, error) {
+	return scanAPIToken(r.db(ctx).QueryRow(ctx, `
+		UPDATE api_tokens t SET last_used_at = NOW()
+		FROM users u
+		WHERE t.token_hash = $1 AND u.user_id = t.user_id AND u.is_active
+			AND t.revoked_at IS NULL AND (t.expires_at IS NULL OR t.expires_at > NOW())
+		RETURNING t.token_id, t.user_id, t.name, t.scopes, t.created_at, t.expires_at, t.last_used_at, t.revoked_at
+	`, tokenHash))
+}
+
+func (r *postgresRepository) GetAPIToken(ctx context.Context, tokenID int64) (*model.APIToken, error) {
+	return scanAPIToken(r.db(ctx).QueryRow(ctx, `SELECT `+apiTokenColumns+` FROM api_tokens WHERE token_id = $1`, tokenID))
+}
+
+// ListAPITokens возвращает токены пользователя (или все при пустом userID), включая отозванные
+func (r *postgresRepository) ListAPITokens(ctx context.Context, userID string) ([]*model.APIToken, error) {
+	rows, err := r.db(ctx).Query(ctx, `
+		SELECT `+apiTokenColumns+` FROM api_tokens
+		WHERE $1 = '' OR user_id = $1
+		ORDER BY token_id
 	`, userID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var prs []*model.PullRequestShort
+	tokens := []*model.APIToken{}
 	for rows.Next() {
-		var pr model.PullRequestShort
-		if err := rows.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status); err != nil {
+		token, err := scanAPIToken(rows)
+		if err != nil {
 			return nil, err
 		}
-		prs = append(prs, &pr)
+		tokens = append(tokens, token)
 	}
 
-	return prs, nil
+	return tokens, rows.Err()
 }
 
-func (r *postgresRepository) PRExists(ctx context.Context, prID string) (bool, error) {
-	var exists bool
-	err := r.pool.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM pull_requests WHERE pull_request_id = $1)", prID).Scan(&exists)
-	return exists, err
+// RevokeAPIToken отзывает токен; повторный отзыв ничего не меняет. С непустым ownerID отзывается
+// только токен этого пользователя: чужой неотличим от несуществующего (ErrTokenNotFound).
+func (r *postgresRepository) RevokeAPIToken(ctx context.Context, tokenID int64, ownerID string) error {
+	return r.audited(ctx, "api_token.revoke", tokenTarget(tokenID), func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, `
+			UPDATE api_tokens SET revoked_at = COALESCE(revoked_at, NOW()) 
+			WHERE token_id = $1 AND ($2 = '' OR user_id = $2)
+		`, tokenID, ownerID)
+		if err != nil {
+			return err
+		}
+		if result.RowsAffected() == 0 {
+			return ErrTokenNotFound
+		}
+		return nil
+	})
 }
-
-func (r *postgresRepository) IsUserAssignedToPR(ctx context.Context, prID string, userID string) (bool, error) {
-	var assigned bool
-	err := r.pool.QueryRow(ctx, `
-		SELECT EXISTS(
-			SELECT 1 FROM pr_reviewers 
-			WHERE pull_request_id = $1 AND user_id = $2
-		)
-	`, prID, userID).Scan(&assigned)
-	return assigned, err
-}
\ No newline at end of file
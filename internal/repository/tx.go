@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// dbtx — общее у пула и транзакции. Begin внутри транзакции открывает точку сохранения,
+// поэтому методы со своей транзакцией работают и внутри InTx.
+type dbtx interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+type txKey struct{}
+
+// db возвращает транзакцию InTx, если она есть в контексте, иначе пул
+func (r *postgresRepository) db(ctx context.Context) dbtx {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return r.pool
+}
+
+// InTx выполняет fn в одной транзакции: все вызовы репозитория с контекстом, переданным в fn, идут в ней.
+// Ошибка fn откатывает всё, что было сделано.
+func (r *postgresRepository) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := r.db(ctx).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"review-service/internal/model"
+	"testing"
+	"time"
+)
+
+// absenceRepo запоминает переданный в репозиторий период отсутствия
+type absenceRepo struct {
+	*reviewRepo
+	from, until *time.Time
+}
+
+func (r *absenceRepo) SetUserAbsence(ctx context.Context, userID string, from, until *time.Time) (*model.User, error) {
+	r.from, r.until = from, until
+	return &model.User{UserID: userID, AbsentFrom: from, AbsentUntil: until}, nil
+}
+
+func TestReassignReviewerSkipsAbsentMembers(t *testing.T) {
+	now := time.Now()
+	from, until := now.Add(-time.Hour), now.Add(24*time.Hour)
+	away := member("away", model.RoleMember, true)
+	away.AbsentFrom, away.AbsentUntil = &from, &until
+	repo := newReviewRepo(&model.Team{TeamName: "backend", Members: []model.TeamMember{
+		member("author", model.RoleMember, true), member("r1", model.RoleMember, true), away, member("r3", model.RoleMember, true),
+	}})
+	repo.prs["pr-1"] = &model.PullRequest{
+		PullRequestID: "pr-1", AuthorID: "author", Status: "OPEN", AssignedReviewers: []string{"r1"},
+	}
+	s := &service{repo: repo, notifier: discardNotifier{}}
+
+	for i := 0; i < 20; i++ {
+		repo.prs["pr-1"].AssignedReviewers = []string{"r1"}
+		_, newReviewer, err := s.ReassignReviewer(context.Background(), "pr-1", "r1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if newReviewer != "r3" {
+			t.Fatalf("replacement = %q, want r3: absent members must not be picked", newReviewer)
+		}
+	}
+}
+
+func TestSetUserAbsence(t *testing.T) {
+	from := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	until := from.Add(7 * 24 * time.Hour)
+
+	t.Run("period", func(t *testing.T) {
+		repo := &absenceRepo{reviewRepo: newReviewRepo()}
+		s := &service{repo: repo, notifier: discardNotifier{}}
+		if _, err := s.SetUserAbsence(context.Background(), "u1", &from, &until); err != nil {
+			t.Fatal(err)
+		}
+		if !repo.from.Equal(from) || !repo.until.Equal(until) {
+			t.Fatalf("stored %v..%v", repo.from, repo.until)
+		}
+	})
+
+	t.Run("starts now without from", func(t *testing.T) {
+		repo := &absenceRepo{reviewRepo: newReviewRepo()}
+		s := &service{repo: repo, notifier: discardNotifier{}}
+		future := time.Now().Add(time.Hour)
+		if _, err := s.SetUserAbsence(context.Background(), "u1", nil, &future); err != nil {
+			t.Fatal(err)
+		}
+		if repo.from == nil || repo.from.After(time.Now()) {
+			t.Fatalf("absence must start now, got %v", repo.from)
+		}
+	})
+
+	t.Run("cleared without until", func(t *testing.T) {
+		repo := &absenceRepo{reviewRepo: newReviewRepo()}
+		s := &service{repo: repo, notifier: discardNotifier{}}
+		if _, err := s.SetUserAbsence(context.Background(), "u1", &from, nil); err != nil {
+			t.Fatal(err)
+		}
+		if repo.from != nil || repo.until != nil {
+			t.Fatalf("absence must be cleared, got %v..%v", repo.from, repo.until)
+		}
+	})
+
+	t.Run("until before from", func(t *testing.T) {
+		s := &service{repo: &absenceRepo{reviewRepo: newReviewRepo()}, notifier: discardNotifier{}}
+		_, err := s.SetUserAbsence(context.Background(), "u1", &until, &from)
+		var bErr BusinessError
+		if !errors.As(err, &bErr) || bErr.Code != "INVALID_INPUT" {
+			t.Fatalf("expected INVALID_INPUT, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+	"review-service/internal/model"
+)
+
+func (s *service) ListAuditLog(ctx context.Context, filter model.AuditFilter) (*model.AuditLog, error) {
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, NewBusinessError("INVALID_INPUT", "from must be before to", nil)
+	}
+	limit, offset, err := page(filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, err
+	}
+	filter.Limit, filter.Offset = limit, offset
+
+	entries, total, err := s.repo.ListAuditLog(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.AuditLog{Entries: entries, Total: total, Limit: limit, Offset: offset}, nil
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"review-service/internal/model"
+	"review-service/internal/repository"
+	"strings"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+func (s *service) GetUser(ctx context.Context, userID string) (*model.User, error) {
+	if userID == "" {
+		return nil, ErrInvalidInput
+	}
+
+	user, err := s.repo.GetUser(ctx, userID)
+	if err != nil {
+		if err == repository.ErrUserNotFound {
+			return nil, NewBusinessError("NOT_FOUND", "user not found", err)
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *service) ListUsers(ctx context.Context, filter model.UserFilter) (*model.UserList, error) {
+	limit, offset, err := page(filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, err
+	}
+	filter.Limit, filter.Offset = limit, offset
+
+	users, total, err := s.repo.ListUsers(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.UserList{Users: users, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+func (s *service) UpdateUser(ctx context.Context, update *model.UserUpdate) (*model.User, error) {
+	if update.UserID == "" {
+		return nil, ErrInvalidInput
+	}
+	if update.Username == nil && update.IsActive == nil {
+		return nil, NewBusinessError("INVALID_INPUT", "nothing to update", nil)
+	}
+	if update.Username != nil {
+		username := strings.TrimSpace(*update.Username)
+		if username == "" {
+			return nil, NewBusinessError("INVALID_INPUT", "username must not be empty", nil)
+		}
+		update.Username = &username
+	}
+
+	user, err := s.repo.UpdateUser(ctx, update)
+	if err != nil {
+		if err == repository.ErrUserNotFound {
+			return nil, NewBusinessError("NOT_FOUND", "user not found", err)
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *service) ListTeams(ctx context.Context, filter model.TeamListFilter) (*model.TeamList, error) {
+	switch filter.SortBy {
+	case "":
+		filter.SortBy = model.TeamSortName
+	case model.TeamSortName, model.TeamSortMembers, model.TeamSortActiveMembers, model.TeamSortOpenReviews:
+	default:
+		return nil, NewBusinessError("INVALID_INPUT", "sort must be team_name, members, active_members or open_reviews", nil)
+	}
+
+	limit, offset, err := page(filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, err
+	}
+	filter.Limit, filter.Offset = limit, offset
+
+	teams, total, err := s.repo.ListTeams(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TeamList{Teams: teams, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+// page проверяет параметры пагинации; нулевой limit заменяется размером страницы по умолчанию
+func page(limit, offset int) (int, int, error) {
+	if limit < 0 || limit > maxPageSize || offset < 0 {
+		return 0, 0, NewBusinessError("INVALID_INPUT", "limit must be between 1 and 200, offset must not be negative", nil)
+	}
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+	return limit, offset, nil
+}
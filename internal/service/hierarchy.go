@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"review-service/internal/model"
+	"review-service/internal/repository"
+)
+
+func (s *service) SetTeamParent(ctx context.Context, teamName, parentTeam string) (*model.Team, error) {
+	if teamName == "" {
+		return nil, ErrInvalidInput
+	}
+
+	if parentTeam != "" {
+		exists, err := s.repo.TeamExists(ctx, parentTeam)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, NewBusinessError("NOT_FOUND", "parent team not found", nil)
+		}
+	}
+
+	if err := s.repo.SetTeamParent(ctx, teamName, parentTeam); err != nil {
+		switch err {
+		case repository.ErrTeamNotFound:
+			return nil, NewBusinessError("NOT_FOUND", "team not found", err)
+		case repository.ErrTeamCycle:
+			return nil, NewBusinessError("TEAM_CYCLE", "parent team is the team itself or one of its sub-teams", err)
+		}
+		return nil, err
+	}
+
+	return s.repo.GetTeam(ctx, teamName)
+}
+
+func (s *service) SetTeamEscalation(ctx context.Context, teamName, escalation string) (*model.Team, error) {
+	if teamName == "" {
+		return nil, ErrInvalidInput
+	}
+	if !validEscalation(escalation) {
+		return nil, NewBusinessError("INVALID_INPUT", "escalation must be none, siblings, parent or siblings_then_parent", nil)
+	}
+
+	if err := s.repo.SetTeamEscalation(ctx, teamName, escalation); err != nil {
+		if err == repository.ErrTeamNotFound {
+			return nil, NewBusinessError("NOT_FOUND", "team not found", err)
+		}
+		return nil, err
+	}
+
+	return s.repo.GetTeam(ctx, teamName)
+}
+
+func (s *service) RenameTeam(ctx context.Context, teamName, newName string) (*model.Team, error) {
+	if teamName == "" || newName == "" {
+		return nil, ErrInvalidInput
+	}
+
+	if err := s.repo.RenameTeam(ctx, teamName, newName); err != nil {
+		switch err {
+		case repository.ErrTeamNotFound:
+			return nil, NewBusinessError("NOT_FOUND", "team not found", err)
+		case repository.ErrTeamExists:
+			return nil, NewBusinessError("TEAM_EXISTS", newName+" already exists", err)
+		}
+		return nil, err
+	}
+
+	return s.repo.GetTeam(ctx, newName)
+}
+
+// DeleteTeam удаляет команду, переводя участников в destinationTeam.
+// Подкоманды при reparentSubTeams переходят к родителю удаляемой команды.
+func (s *service) DeleteTeam(ctx context.Context, teamName, destinationTeam string, reparentSubTeams bool) error {
+	if teamName == "" {
+		return ErrInvalidInput
+	}
+	if destinationTeam == teamName {
+		return NewBusinessError("INVALID_INPUT", "destination team must differ from the deleted team", nil)
+	}
+
+	if err := s.repo.DeleteTeam(ctx, teamName, destinationTeam, reparentSubTeams); err != nil {
+		switch err {
+		case repository.ErrTeamNotFound:
+			return NewBusinessError("NOT_FOUND", "team or destination team not found", err)
+		case repository.ErrDestinationRequired:
+			return NewBusinessError("INVALID_INPUT", "team has members, destination_team is required", err)
+		case repository.ErrTeamHasSubTeams:
+			return NewBusinessError("TEAM_HAS_SUBTEAMS", "team has sub-teams, set reparent_subteams to move them to its parent", err)
+		case repository.ErrTeamHasOpenPRs:
+			return NewBusinessError("TEAM_HAS_OPEN_PRS", "team members have open pull requests", err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// GetTeamTree возвращает поддерево с корнем rootTeam или весь лес команд, если rootTeam пуст
+func (s *service) GetTeamTree(ctx context.Context, rootTeam string) ([]*model.TeamTreeNode, error) {
+	teams, err := s.repo.ListTeamHierarchy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[string]*model.TeamTreeNode, len(teams))
+	for _, team := range teams {
+		nodes[team.TeamName] = &model.TeamTreeNode{
+			TeamName:   team.TeamName,
+			Escalation: team.Escalation,
+			SubTeams:   []*model.TeamTreeNode{},
+		}
+	}
+
+	roots := []*model.TeamTreeNode{}
+	for _, team := range teams {
+		node := nodes[team.TeamName]
+		if parent, ok := nodes[team.ParentTeam]; ok {
+			parent.SubTeams = append(parent.SubTeams, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+
+	if rootTeam == "" {
+		return roots, nil
+	}
+
+	root, ok := nodes[rootTeam]
+	if !ok {
+		return nil, NewBusinessError("NOT_FOUND", "team not found", nil)
+	}
+	return []*model.TeamTreeNode{root}, nil
+}
+
+// escalationTeams возвращает команды, из которых можно добирать ревьюеров, в порядке приоритета
+func (s *service) escalationTeams(ctx context.Context, team *model.Team) ([]string, error) {
+	if team.ParentTeam == "" {
+		return nil, nil
+	}
+
+	var teamNames []string
+	switch team.Escalation {
+	case model.EscalationSiblings, model.EscalationSiblingsThenParent:
+		siblings, err := s.repo.GetSubTeams(ctx, team.ParentTeam)
+		if err != nil {
+			return nil, err
+		}
+		for _, sibling := range siblings {
+			if sibling != team.TeamName {
+				teamNames = append(teamNames, sibling)
+			}
+		}
+	}
+
+	switch team.Escalation {
+	case model.EscalationParent, model.EscalationSiblingsThenParent:
+		teamNames = append(teamNames, team.ParentTeam)
+	}
+
+	return teamNames, nil
+}
+
+func validEscalation(escalation string) bool {
+	switch escalation {
+	case model.EscalationNone, model.EscalationSiblings, model.EscalationParent, model.EscalationSiblingsThenParent:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"review-service/internal/model"
+	"review-service/internal/repository"
+	"slices"
+	"time"
+)
+
+func (s *service) GetPullRequestHistory(ctx context.Context, prID string) (*model.PullRequestHistory, error) {
+	if prID == "" {
+		return nil, ErrInvalidInput
+	}
+
+	exists, err := s.repo.PRExists(ctx, prID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, NewBusinessError("NOT_FOUND", "PR not found", nil)
+	}
+
+	events, err := s.repo.GetPullRequestHistory(ctx, prID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.PullRequestHistory{PullRequestID: prID, Events: events}, nil
+}
+
+// GetPullRequestAsOf восстанавливает статус, ревьюеров и их вердикты PR на момент asOf по его истории.
+// Название и автор берутся текущие: в истории они не меняются.
+func (s *service) GetPullRequestAsOf(ctx context.Context, prID string, asOf time.Time) (*model.PullRequest, error) {
+	if prID == "" {
+		return nil, ErrInvalidInput
+	}
+
+	current, err := s.repo.GetPullRequest(ctx, prID)
+	if err != nil {
+		if err == repository.ErrPRNotFound {
+			return nil, NewBusinessError("NOT_FOUND", "PR not found", err)
+		}
+		return nil, err
+	}
+
+	events, err := s.repo.GetPullRequestHistory(ctx, prID, &asOf)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 || events[0].Type != model.PRHistoryCreated {
+		return nil, NewBusinessError("NOT_FOUND", "PR did not exist at as_of", nil)
+	}
+
+	return replayPullRequest(current, events), nil
+}
+
+// replayPullRequest применяет события истории к PR с названием и автором из pr.
+// Вердикт живёт, пока ревьюер назначен: снятие и переназначение его сбрасывают.
+func replayPullRequest(pr *model.PullRequest, events []*model.PullRequestHistoryEvent) *model.PullRequest {
+	state := &model.PullRequest{
+		PullRequestID:     pr.PullRequestID,
+		PullRequestName:   pr.PullRequestName,
+		AuthorID:          pr.AuthorID,
+		AssignedReviewers: []string{},
+	}
+
+	for _, event := range events {
+		reviewers := &state.AssignedReviewers
+		if event.IsShadow {
+			reviewers = &state.ShadowReviewers
+		}
+
+		switch event.Type {
+		case model.PRHistoryCreated:
+			createdAt := event.OccurredAt
+			state.Status, state.CreatedAt = "OPEN", &createdAt
+		case model.PRHistoryAssigned:
+			*reviewers = append(*reviewers, event.UserID)
+			delete(state.Verdicts, event.UserID)
+		case model.PRHistoryUnassigned:
+			*reviewers = slices.DeleteFunc(*reviewers, func(id string) bool { return id == event.UserID })
+			delete(state.Verdicts, event.UserID)
+		case model.PRHistoryReassigned:
+			delete(state.Verdicts, event.OldUserID)
+			delete(state.Verdicts, event.UserID)
+			if i := slices.Index(*reviewers, event.OldUserID); i >= 0 {
+				(*reviewers)[i] = event.UserID
+			} else {
+				*reviewers = append(*reviewers, event.UserID)
+			}
+		case model.PRHistoryMerged:
+			mergedAt := event.OccurredAt
+			state.Status, state.MergedAt = "MERGED", &mergedAt
+		case model.PRHistoryClosed:
+			state.Status = "CLOSED"
+		case model.PRHistoryReopened:
+			state.Status = "OPEN"
+		case model.PRHistoryVerdict:
+			if state.Verdicts == nil {
+				state.Verdicts = map[string]string{}
+			}
+			state.Verdicts[event.UserID] = event.Verdict
+		}
+	}
+	state.QuorumReached = state.HasQuorum()
+
+	return state
+}
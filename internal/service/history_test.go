@@ -0,0 +1,38 @@
+package service
+
+import (
+	"review-service/internal/model"
+	"testing"
+	"time"
+)
+
+func TestReplayPullRequestVerdicts(t *testing.T) {
+	start := time.Date(2026, 1, 14, 10, 0, 0, 0, time.UTC)
+	events := []*model.PullRequestHistoryEvent{
+		{Type: model.PRHistoryCreated, UserID: "author", OccurredAt: start},
+		{Type: model.PRHistoryAssigned, UserID: "r1"},
+		{Type: model.PRHistoryAssigned, UserID: "r2"},
+		{Type: model.PRHistoryAssigned, UserID: "t1", IsShadow: true},
+		{Type: model.PRHistoryVerdict, UserID: "r1", Verdict: model.VerdictApproved},
+		{Type: model.PRHistoryVerdict, UserID: "t1", IsShadow: true, Verdict: model.VerdictChangesRequested},
+		{Type: model.PRHistoryVerdict, UserID: "r2", Verdict: model.VerdictChangesRequested},
+		{Type: model.PRHistoryReassigned, UserID: "r3", OldUserID: "r2"},
+	}
+	pr := &model.PullRequest{PullRequestID: "pr-1", AuthorID: "author"}
+
+	state := replayPullRequest(pr, events)
+	if state.Verdicts["r1"] != model.VerdictApproved || state.Verdicts["t1"] != model.VerdictChangesRequested {
+		t.Fatalf("verdicts = %v", state.Verdicts)
+	}
+	if _, ok := state.Verdicts["r2"]; ok {
+		t.Fatalf("verdict of the replaced reviewer must be dropped: %v", state.Verdicts)
+	}
+	if state.QuorumReached {
+		t.Fatal("quorum reached before r3 approved")
+	}
+
+	events = append(events, &model.PullRequestHistoryEvent{Type: model.PRHistoryVerdict, UserID: "r3", Verdict: model.VerdictApproved})
+	if state := replayPullRequest(pr, events); !state.QuorumReached {
+		t.Fatalf("quorum not reached: reviewers %v, verdicts %v", state.AssignedReviewers, state.Verdicts)
+	}
+}
@@ -0,0 +1,378 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"review-service/internal/model"
+	"review-service/internal/repository"
+	"review-service/internal/teamfile"
+	"strconv"
+	"strings"
+)
+
+// ImportTeams разбирает файл импорта, проверяет его и строит список изменений относительно текущего состояния.
+// При ошибках или dryRun ничего не применяется; иначе изменения применяются одной транзакцией.
+// Импорт только добавляет и обновляет: команды, пользователи и членства вне файла не удаляются.
+func (s *service) ImportTeams(ctx context.Context, format string, data []byte, dryRun bool) (*model.ImportResult, error) {
+	result := &model.ImportResult{DryRun: dryRun, Changes: []model.ImportChange{}}
+
+	teams, errs := teamfile.Parse(format, data)
+	if len(errs) > 0 {
+		result.Errors = errs
+		return result, nil
+	}
+
+	plan := &importPlan{service: s, teams: teams}
+	if err := plan.build(ctx); err != nil {
+		return nil, err
+	}
+	result.Changes = plan.changes
+	result.Errors = plan.errors
+	if len(result.Errors) > 0 || dryRun || len(result.Changes) == 0 {
+		return result, nil
+	}
+
+	if err := s.repo.ApplyImport(ctx, plan.teams); err != nil {
+		if err == repository.ErrTeamCycle {
+			result.Errors = []model.ImportError{{Message: "import creates a cycle in team hierarchy"}}
+			return result, nil
+		}
+		return nil, err
+	}
+	result.Applied = true
+
+	return result, nil
+}
+
+// ExportTeams выгружает все команды с участниками в виде, пригодном для повторного импорта
+func (s *service) ExportTeams(ctx context.Context) ([]model.ImportTeam, error) {
+	hierarchy, err := s.repo.ListTeamHierarchy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	teams := make([]model.ImportTeam, 0, len(hierarchy))
+	for _, entry := range hierarchy {
+		team, err := s.repo.GetTeam(ctx, entry.TeamName)
+		if err != nil {
+			return nil, err
+		}
+
+		mentorship := team.Mentorship
+		exported := model.ImportTeam{
+			TeamName:              team.TeamName,
+			ParentTeam:            team.ParentTeam,
+			Escalation:            team.Escalation,
+			RequiredReviewerRoles: team.RequiredReviewerRoles,
+			Mentorship:            &mentorship,
+		}
+		for _, member := range team.Members {
+			isActive := member.IsActive
+			exported.Members = append(exported.Members, model.ImportMember{
+				UserID:    member.UserID,
+				Username:  member.Username,
+				IsActive:  &isActive,
+				Role:      member.Role,
+				IsPrimary: member.IsPrimary,
+			})
+		}
+		teams = append(teams, exported)
+	}
+
+	return teams, nil
+}
+
+type importPlan struct {
+	service *service
+	teams   []model.ImportTeam
+	changes []model.ImportChange
+	errors  []model.ImportError
+}
+
+func (p *importPlan) fail(line int, format string, args ...interface{}) {
+	p.errors = append(p.errors, model.ImportError{Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+func (p *importPlan) change(line int, action, target string, details []string) {
+	p.changes = append(p.changes, model.ImportChange{Line: line, Action: action, Target: target, Detail: strings.Join(details, ", ")})
+}
+
+func (p *importPlan) build(ctx context.Context) error {
+	current := map[string]*model.Team{}
+	parents := map[string]string{}
+	hierarchy, err := p.service.repo.ListTeamHierarchy(ctx)
+	if err != nil {
+		return err
+	}
+	for _, team := range hierarchy {
+		parents[team.TeamName] = team.ParentTeam
+	}
+
+	seen := map[string]int{}
+	for i := range p.teams {
+		team := &p.teams[i]
+		if team.TeamName == "" {
+			p.fail(team.Line, "team_name is required")
+			continue
+		}
+		if line, ok := seen[team.TeamName]; ok {
+			p.fail(team.Line, "team %s is already described at line %d", team.TeamName, line)
+			continue
+		}
+		seen[team.TeamName] = team.Line
+
+		if team.Escalation != "" && !validEscalation(team.Escalation) {
+			p.fail(team.Line, "escalation must be none, siblings, parent or siblings_then_parent")
+		}
+		if team.RequiredReviewerRoles != nil {
+			roles, err := normalizeReviewerRoles(team.RequiredReviewerRoles)
+			if err != nil {
+				p.fail(team.Line, "%s", err.(BusinessError).Message)
+			}
+			team.RequiredReviewerRoles = roles
+		}
+
+		existing, err := p.service.repo.GetTeam(ctx, team.TeamName)
+		if err != nil && err != repository.ErrTeamNotFound {
+			return err
+		}
+		if existing != nil {
+			current[team.TeamName] = existing
+		} else {
+			parents[team.TeamName] = ""
+		}
+		if team.ParentTeam != "" {
+			parents[team.TeamName] = team.ParentTeam
+		}
+	}
+
+	for _, team := range p.teams {
+		if team.ParentTeam == "" {
+			continue
+		}
+		if _, ok := parents[team.ParentTeam]; !ok {
+			p.fail(team.Line, "parent team %s not found", team.ParentTeam)
+			continue
+		}
+		// Поднимаемся по итоговой иерархии; больше len(parents) шагов возможно только при цикле
+		ancestor := team.ParentTeam
+		for steps := 0; ancestor != "" && steps <= len(parents); steps++ {
+			if ancestor == team.TeamName {
+				p.fail(team.Line, "parent team %s creates a cycle", team.ParentTeam)
+				break
+			}
+			ancestor = parents[ancestor]
+		}
+	}
+
+	if err := p.resolveMembers(ctx); err != nil {
+		return err
+	}
+	if len(p.errors) > 0 {
+		return nil
+	}
+
+	for _, team := range p.teams {
+		p.diffTeam(team, current[team.TeamName])
+	}
+	return nil
+}
+
+// resolveMembers проверяет участников и выставляет итоговый IsPrimary: явно указанная основная команда,
+// иначе текущая основная команда пользователя, иначе первая команда пользователя в файле
+func (p *importPlan) resolveMembers(ctx context.Context) error {
+	type userEntry struct {
+		first    *model.ImportMember
+		primary  string
+		existing *model.User
+	}
+	users := map[string]*userEntry{}
+	var order []string
+
+	for i := range p.teams {
+		team := &p.teams[i]
+		inTeam := map[string]bool{}
+		for j := range team.Members {
+			member := &team.Members[j]
+			if member.UserID == "" {
+				p.fail(member.Line, "user_id is required")
+				continue
+			}
+			if inTeam[member.UserID] {
+				p.fail(member.Line, "user %s is listed twice in team %s", member.UserID, team.TeamName)
+				continue
+			}
+			inTeam[member.UserID] = true
+			if member.Role != "" && !validRole(member.Role) {
+				p.fail(member.Line, "role must be lead, senior, member or trainee")
+			}
+
+			entry, ok := users[member.UserID]
+			if !ok {
+				existing, err := p.service.repo.GetUser(ctx, member.UserID)
+				if err != nil && err != repository.ErrUserNotFound {
+					return err
+				}
+				entry = &userEntry{first: member, existing: existing}
+				users[member.UserID] = entry
+				order = append(order, member.UserID)
+			} else {
+				if member.Username != "" && entry.first.Username != "" && member.Username != entry.first.Username {
+					p.fail(member.Line, "username of %s differs from line %d", member.UserID, entry.first.Line)
+				}
+				if member.IsActive != nil && entry.first.IsActive != nil && *member.IsActive != *entry.first.IsActive {
+					p.fail(member.Line, "is_active of %s differs from line %d", member.UserID, entry.first.Line)
+				}
+			}
+
+			if member.IsPrimary {
+				if entry.primary != "" {
+					p.fail(member.Line, "user %s has several primary teams", member.UserID)
+				}
+				entry.primary = team.TeamName
+			}
+		}
+	}
+
+	for _, userID := range order {
+		entry := users[userID]
+		if entry.primary == "" && entry.existing != nil {
+			entry.primary = entry.existing.TeamName
+		}
+		if entry.primary == "" {
+			entry.primary = firstTeamOf(p.teams, userID)
+		}
+		// Прежняя основная команда, не упомянутая в файле для этого пользователя, становится дополнительной
+		if entry.existing != nil && entry.existing.TeamName != "" && entry.existing.TeamName != entry.primary &&
+			!listsMember(p.teams, entry.existing.TeamName, userID) {
+			p.change(entry.first.Line, model.ImportUpdateMembership, userID+"@"+entry.existing.TeamName, []string{"is_primary: true -> false"})
+		}
+	}
+	for i := range p.teams {
+		for j := range p.teams[i].Members {
+			member := &p.teams[i].Members[j]
+			if entry, ok := users[member.UserID]; ok {
+				member.IsPrimary = entry.primary == p.teams[i].TeamName
+			}
+		}
+	}
+
+	for _, userID := range order {
+		entry := users[userID]
+		username, isActive := entry.first.Username, entry.first.IsActive
+		for _, team := range p.teams {
+			for _, member := range team.Members {
+				if member.UserID == userID {
+					if username == "" {
+						username = member.Username
+					}
+					if isActive == nil {
+						isActive = member.IsActive
+					}
+				}
+			}
+		}
+
+		if entry.existing == nil {
+			if username == "" {
+				p.fail(entry.first.Line, "username is required for new user %s", userID)
+				continue
+			}
+			active := isActive == nil || *isActive
+			p.change(entry.first.Line, model.ImportCreateUser, userID, []string{"username: " + username, "is_active: " + strconv.FormatBool(active)})
+			continue
+		}
+		var details []string
+		if username != "" && username != entry.existing.Username {
+			details = append(details, "username: "+entry.existing.Username+" -> "+username)
+		}
+		if isActive != nil && *isActive != entry.existing.IsActive {
+			details = append(details, "is_active: "+strconv.FormatBool(entry.existing.IsActive)+" -> "+strconv.FormatBool(*isActive))
+		}
+		if len(details) > 0 {
+			p.change(entry.first.Line, model.ImportUpdateUser, userID, details)
+		}
+	}
+
+	return nil
+}
+
+func (p *importPlan) diffTeam(team model.ImportTeam, existing *model.Team) {
+	members := map[string]model.TeamMember{}
+	if existing == nil {
+		details := []string{}
+		if team.ParentTeam != "" {
+			details = append(details, "parent_team: "+team.ParentTeam)
+		}
+		p.change(team.Line, model.ImportCreateTeam, team.TeamName, details)
+	} else {
+		var details []string
+		if team.ParentTeam != "" && team.ParentTeam != existing.ParentTeam {
+			details = append(details, "parent_team: "+existing.ParentTeam+" -> "+team.ParentTeam)
+		}
+		if team.Escalation != "" && team.Escalation != existing.Escalation {
+			details = append(details, "escalation: "+existing.Escalation+" -> "+team.Escalation)
+		}
+		if team.RequiredReviewerRoles != nil && strings.Join(team.RequiredReviewerRoles, ",") != strings.Join(existing.RequiredReviewerRoles, ",") {
+			details = append(details, "required_reviewer_roles: ["+strings.Join(existing.RequiredReviewerRoles, ",")+"] -> ["+strings.Join(team.RequiredReviewerRoles, ",")+"]")
+		}
+		if team.Mentorship != nil && *team.Mentorship != existing.Mentorship {
+			details = append(details, "mentorship: "+strconv.FormatBool(existing.Mentorship)+" -> "+strconv.FormatBool(*team.Mentorship))
+		}
+		if len(details) > 0 {
+			p.change(team.Line, model.ImportUpdateTeam, team.TeamName, details)
+		}
+		for _, member := range existing.Members {
+			members[member.UserID] = member
+		}
+	}
+
+	for _, member := range team.Members {
+		target := member.UserID + "@" + team.TeamName
+		current, ok := members[member.UserID]
+		if !ok {
+			role := member.Role
+			if role == "" {
+				role = model.RoleMember
+			}
+			p.change(member.Line, model.ImportAddMembership, target, []string{"role: " + role, "is_primary: " + strconv.FormatBool(member.IsPrimary)})
+			continue
+		}
+
+		var details []string
+		if member.Role != "" && member.Role != current.Role {
+			details = append(details, "role: "+current.Role+" -> "+member.Role)
+		}
+		if member.IsPrimary != current.IsPrimary {
+			details = append(details, "is_primary: "+strconv.FormatBool(current.IsPrimary)+" -> "+strconv.FormatBool(member.IsPrimary))
+		}
+		if len(details) > 0 {
+			p.change(member.Line, model.ImportUpdateMembership, target, details)
+		}
+	}
+}
+
+func listsMember(teams []model.ImportTeam, teamName, userID string) bool {
+	for _, team := range teams {
+		if team.TeamName != teamName {
+			continue
+		}
+		for _, member := range team.Members {
+			if member.UserID == userID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func firstTeamOf(teams []model.ImportTeam, userID string) string {
+	for _, team := range teams {
+		for _, member := range team.Members {
+			if member.UserID == userID {
+				return team.TeamName
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"review-service/internal/model"
+	"review-service/internal/repository"
+)
+
+func (s *service) LinkUserIdentity(ctx context.Context, identity *model.UserIdentity) (*model.UserIdentity, error) {
+	if identity.Provider == "" || identity.ExternalLogin == "" || identity.UserID == "" {
+		return nil, ErrInvalidInput
+	}
+
+	exists, err := s.repo.UserExists(ctx, identity.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, NewBusinessError("NOT_FOUND", "user not found", nil)
+	}
+
+	if err := s.repo.SetUserIdentity(ctx, identity); err != nil {
+		return nil, err
+	}
+
+	return identity, nil
+}
+
+// HandlePullRequestEvent применяет событие code host'а ровно один раз на delivery id.
+// Отметка о доставке и само изменение пишутся в одной транзакции: если обработка не удалась
+// или процесс упал, доставки как будто не было, и повторная пройдёт заново.
+// Метрики и уведомления — только после фиксации, чтобы откат и повторная доставка их не дублировали.
+func (s *service) HandlePullRequestEvent(ctx context.Context, event *model.PullRequestEvent) (*model.WebhookResult, error) {
+	if event.Provider == "" || event.DeliveryID == "" || event.PullRequestID == "" {
+		return nil, ErrInvalidInput
+	}
+
+	result := &model.WebhookResult{DeliveryID: event.DeliveryID, Status: "processed"}
+	var finish func()
+	err := s.repo.InTx(ctx, func(ctx context.Context) error {
+		fresh, err := s.repo.RegisterWebhookDelivery(ctx, event.Provider, event.DeliveryID)
+		if err != nil {
+			return err
+		}
+		if !fresh {
+			result.Status = "duplicate"
+			return nil
+		}
+
+		result.PullRequest, finish, err = s.applyPullRequestEvent(ctx, event)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if finish != nil {
+		finish()
+	}
+	return result, nil
+}
+
+// applyPullRequestEvent применяет событие внутри транзакции. finish (может быть nil) досылает метрики
+// и уведомления и вызывается только после фиксации.
+func (s *service) applyPullRequestEvent(ctx context.Context, event *model.PullRequestEvent) (*model.PullRequest, func(), error) {
+	switch event.Action {
+	case model.PREventOpen:
+		authorID, err := s.resolveAuthor(ctx, event)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		pr, err := s.createPullRequest(ctx, event.PullRequestID, event.PullRequestName, authorID)
+		if businessErr, ok := err.(BusinessError); ok && businessErr.Code == "PR_EXISTS" {
+			existing, err := s.repo.GetPullRequest(ctx, event.PullRequestID)
+			return existing, nil, err
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		link := &model.PullRequestLink{
+			PullRequestID: pr.PullRequestID,
+			Provider:      event.Provider,
+			Repository:    event.Repository,
+			Number:        event.Number,
+		}
+		if err := s.repo.SetPullRequestLink(ctx, link); err != nil {
+			return nil, nil, err
+		}
+		s.syncReviewers(ctx, pr.PullRequestID, pr.AssignedReviewers, nil)
+
+		return pr, func() { s.finishPullRequestCreate(pr) }, nil
+	case model.PREventMerge:
+		merged, justMerged, err := s.mergePullRequest(ctx, event.PullRequestID)
+		if err != nil || !justMerged {
+			return merged, nil, err
+		}
+		return merged, func() { s.finishPullRequestMerge(merged) }, nil
+	case model.PREventClose:
+		pr, err := s.ClosePullRequest(ctx, event.PullRequestID)
+		return pr, nil, err
+	case model.PREventReopen:
+		pr, err := s.ReopenPullRequest(ctx, event.PullRequestID)
+		return pr, nil, err
+	default:
+		return nil, nil, ErrInvalidInput
+	}
+}
+
+// resolveAuthor находит автора PR по числовому id на code host'е, а если id ещё не привязан — по логину.
+// Во втором случае id запоминается, чтобы узнавать автора и в событиях, которые вызвал не он.
+func (s *service) resolveAuthor(ctx context.Context, event *model.PullRequestEvent) (string, error) {
+	if event.AuthorExternalID != "" {
+		userID, err := s.repo.GetUserIDByExternalID(ctx, event.Provider, event.AuthorExternalID)
+		if err == nil {
+			return userID, nil
+		}
+		if err != repository.ErrIdentityNotFound {
+			return "", err
+		}
+		if event.AuthorLogin == "" {
+			return "", NewBusinessError("NOT_FOUND", "no user linked to "+event.Provider+" user id "+event.AuthorExternalID, err)
+		}
+	}
+
+	userID, err := s.resolveIdentity(ctx, event.Provider, event.AuthorLogin)
+	if err != nil {
+		return "", err
+	}
+
+	if event.AuthorExternalID != "" {
+		identity := &model.UserIdentity{
+			Provider:      event.Provider,
+			ExternalLogin: event.AuthorLogin,
+			ExternalID:    event.AuthorExternalID,
+			UserID:        userID,
+		}
+		if err := s.repo.SetUserIdentity(ctx, identity); err != nil {
+			return "", err
+		}
+	}
+
+	return userID, nil
+}
+
+func (s *service) resolveIdentity(ctx context.Context, provider, externalLogin string) (string, error) {
+	if externalLogin == "" {
+		return "", ErrInvalidInput
+	}
+
+	userID, err := s.repo.GetUserIDByIdentity(ctx, provider, externalLogin)
+	if err != nil {
+		if err == repository.ErrIdentityNotFound {
+			return "", NewBusinessError("NOT_FOUND", "no user linked to "+provider+" login "+externalLogin, err)
+		}
+		return "", err
+	}
+
+	return userID, nil
+}
+
+// syncReviewers ставит в очередь отправку изменений ревьюеров в code host, если PR пришёл оттуда.
+// Ошибки только логируются: сбой интеграции не должен ломать основную операцию.
+func (s *service) syncReviewers(ctx context.Context, prID string, added, removed []string) {
+	if _, err := s.repo.GetPullRequestLink(ctx, prID); err != nil {
+		if err != repository.ErrPRLinkNotFound {
+			slog.ErrorContext(ctx, "failed to load code host link", "pull_request_id", prID, "error", err)
+		}
+		return
+	}
+
+	if len(removed) > 0 {
+		if err := s.repo.EnqueueReviewerSync(ctx, prID, model.ReviewerSyncRemove, removed); err != nil {
+			slog.ErrorContext(ctx, "failed to enqueue reviewer removal", "pull_request_id", prID, "error", err)
+		}
+	}
+	if len(added) > 0 {
+		if err := s.repo.EnqueueReviewerSync(ctx, prID, model.ReviewerSyncRequest, added); err != nil {
+			slog.ErrorContext(ctx, "failed to enqueue reviewer request", "pull_request_id", prID, "error", err)
+		}
+	}
+}
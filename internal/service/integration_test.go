@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"review-service/internal/model"
+	"review-service/internal/repository"
+	"testing"
+)
+
+// webhookRepo — репозиторий в памяти для обработки вебхуков. InTx откатывает отметки о доставках
+// и созданные PR, если fn вернула ошибку, как это делает транзакция.
+type webhookRepo struct {
+	repository.Repository
+	deliveries map[string]bool
+	prs        map[string]*model.PullRequest
+	identities []model.UserIdentity
+	merges     int
+}
+
+func newWebhookRepo() *webhookRepo {
+	return &webhookRepo{deliveries: map[string]bool{}, prs: map[string]*model.PullRequest{}}
+}
+
+func (r *webhookRepo) GetUserIDByIdentity(ctx context.Context, provider, externalLogin string) (string, error) {
+	for _, identity := range r.identities {
+		if identity.Provider == provider && identity.ExternalLogin == externalLogin {
+			return identity.UserID, nil
+		}
+	}
+	return "", repository.ErrIdentityNotFound
+}
+
+func (r *webhookRepo) GetUserIDByExternalID(ctx context.Context, provider, externalID string) (string, error) {
+	for _, identity := range r.identities {
+		if identity.Provider == provider && identity.ExternalID == externalID {
+			return identity.UserID, nil
+		}
+	}
+	return "", repository.ErrIdentityNotFound
+}
+
+func (r *webhookRepo) SetUserIdentity(ctx context.Context, identity *model.UserIdentity) error {
+	for i := range r.identities {
+		if r.identities[i].Provider == identity.Provider && r.identities[i].ExternalLogin == identity.ExternalLogin {
+			r.identities[i] = *identity
+			return nil
+		}
+	}
+	r.identities = append(r.identities, *identity)
+	return nil
+}
+
+func (r *webhookRepo) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	saved, savedPRs := maps.Clone(r.deliveries), maps.Clone(r.prs)
+	if err := fn(ctx); err != nil {
+		r.deliveries, r.prs = saved, savedPRs
+		return err
+	}
+	return nil
+}
+
+func (r *webhookRepo) RegisterWebhookDelivery(ctx context.Context, provider, deliveryID string) (bool, error) {
+	key := provider + "/" + deliveryID
+	if r.deliveries[key] {
+		return false, nil
+	}
+	r.deliveries[key] = true
+	return true, nil
+}
+
+func (r *webhookRepo) GetPullRequest(ctx context.Context, prID string) (*model.PullRequest, error) {
+	pr, ok := r.prs[prID]
+	if !ok {
+		return nil, repository.ErrPRNotFound
+	}
+	copied := *pr
+	return &copied, nil
+}
+
+func (r *webhookRepo) MergePullRequest(ctx context.Context, prID string) error {
+	r.prs[prID].Status = "MERGED"
+	r.merges++
+	return nil
+}
+
+type discardNotifier struct{}
+
+func (discardNotifier) Notify(*model.NotificationEvent) {}
+
+func mergeEvent(deliveryID string) *model.PullRequestEvent {
+	return &model.PullRequestEvent{
+		Provider:      "github",
+		DeliveryID:    deliveryID,
+		Action:        model.PREventMerge,
+		PullRequestID: "github:acme/payments#42",
+	}
+}
+
+func TestHandlePullRequestEventDuplicateDelivery(t *testing.T) {
+	repo := newWebhookRepo()
+	repo.prs["github:acme/payments#42"] = &model.PullRequest{PullRequestID: "github:acme/payments#42", Status: "OPEN"}
+	svc := NewService(repo, discardNotifier{})
+
+	first, err := svc.HandlePullRequestEvent(context.Background(), mergeEvent("delivery-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Status != "processed" || first.PullRequest == nil || first.PullRequest.Status != "MERGED" {
+		t.Fatalf("first delivery: got %+v", first)
+	}
+
+	second, err := svc.HandlePullRequestEvent(context.Background(), mergeEvent("delivery-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Status != "duplicate" || second.PullRequest != nil {
+		t.Fatalf("redelivery: got %+v", second)
+	}
+	if repo.merges != 1 {
+		t.Fatalf("expected one merge, got %d", repo.merges)
+	}
+}
+
+func TestHandlePullRequestEventFailureAllowsRedelivery(t *testing.T) {
+	repo := newWebhookRepo()
+	svc := NewService(repo, discardNotifier{})
+
+	if _, err := svc.HandlePullRequestEvent(context.Background(), mergeEvent("delivery-1")); err == nil {
+		t.Fatal("expected error for unknown PR")
+	}
+
+	repo.prs["github:acme/payments#42"] = &model.PullRequest{PullRequestID: "github:acme/payments#42", Status: "OPEN"}
+	result, err := svc.HandlePullRequestEvent(context.Background(), mergeEvent("delivery-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != "processed" || repo.merges != 1 {
+		t.Fatalf("redelivery after failure: got %+v, merges %d", result, repo.merges)
+	}
+}
+
+// openRepo дополняет webhookRepo созданием PR; SetPullRequestLink падает, пока задан linkErr
+type openRepo struct {
+	*webhookRepo
+	team    *model.Team
+	linkErr error
+}
+
+func (r *openRepo) GetUser(ctx context.Context, userID string) (*model.User, error) {
+	return &model.User{UserID: userID, TeamName: r.team.TeamName, IsActive: true}, nil
+}
+
+func (r *openRepo) GetTeam(ctx context.Context, teamName string) (*model.Team, error) {
+	return r.team, nil
+}
+
+func (r *openRepo) CreatePullRequest(ctx context.Context, pr *model.PullRequest, reviewers []string) error {
+	if _, ok := r.prs[pr.PullRequestID]; ok {
+		return repository.ErrPRExists
+	}
+	r.prs[pr.PullRequestID] = pr
+	return nil
+}
+
+func (r *openRepo) SetPullRequestLink(ctx context.Context, link *model.PullRequestLink) error {
+	return r.linkErr
+}
+
+func (r *openRepo) GetPullRequestLink(ctx context.Context, prID string) (*model.PullRequestLink, error) {
+	return &model.PullRequestLink{PullRequestID: prID}, nil
+}
+
+func (r *openRepo) EnqueueReviewerSync(ctx context.Context, prID string, action string, userIDs []string) error {
+	return nil
+}
+
+func TestHandlePullRequestEventNotifiesOnlyAfterCommit(t *testing.T) {
+	repo := &openRepo{
+		webhookRepo: newWebhookRepo(),
+		team: &model.Team{TeamName: "backend", Members: []model.TeamMember{
+			member("author", model.RoleMember, true), member("r1", model.RoleMember, true),
+		}},
+		linkErr: errors.New("link failed"),
+	}
+	repo.identities = []model.UserIdentity{{Provider: "github", ExternalLogin: "gh-author", UserID: "author"}}
+	notifier := &recordingNotifier{}
+	svc := NewService(repo, notifier)
+	event := &model.PullRequestEvent{
+		Provider: "github", DeliveryID: "delivery-1", Action: model.PREventOpen,
+		PullRequestID: "github:acme/payments#42", PullRequestName: "Fix", AuthorLogin: "gh-author",
+	}
+
+	if _, err := svc.HandlePullRequestEvent(context.Background(), event); err == nil {
+		t.Fatal("expected link error")
+	}
+	if len(notifier.events) != 0 || len(repo.prs) != 0 {
+		t.Fatalf("rolled back delivery left %d notifications and PRs %v", len(notifier.events), repo.prs)
+	}
+
+	repo.linkErr = nil
+	if _, err := svc.HandlePullRequestEvent(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+	if len(notifier.events) != 1 || notifier.events[0].Type != model.NotificationAssigned {
+		t.Fatalf("expected one assignment notification after redelivery, got %+v", notifier.events)
+	}
+}
+
+func TestResolveAuthorByExternalID(t *testing.T) {
+	repo := newWebhookRepo()
+	repo.identities = []model.UserIdentity{{Provider: "gitlab", ExternalLogin: "gl-carol", UserID: "u1"}}
+	svc := &service{repo: repo, notifier: discardNotifier{}}
+	ctx := context.Background()
+
+	// Событие вызвал не автор: без запомненного id автора не узнать
+	byOther := &model.PullRequestEvent{Provider: "gitlab", AuthorExternalID: "301"}
+	if _, err := svc.resolveAuthor(ctx, byOther); err == nil {
+		t.Fatal("expected NOT_FOUND before the author's id is known")
+	}
+
+	// Событие автора связывает его логин с id
+	byAuthor := &model.PullRequestEvent{Provider: "gitlab", AuthorLogin: "gl-carol", AuthorExternalID: "301"}
+	userID, err := svc.resolveAuthor(ctx, byAuthor)
+	if err != nil || userID != "u1" {
+		t.Fatalf("by author: got %q, %v", userID, err)
+	}
+
+	userID, err = svc.resolveAuthor(ctx, byOther)
+	if err != nil || userID != "u1" {
+		t.Fatalf("by other user after id is known: got %q, %v", userID, err)
+	}
+}
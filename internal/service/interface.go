@@ -3,26 +3,103 @@ package service
 import (
 	"context"
 	"review-service/internal/model"
+	"time"
 )
 
 type Service interface {
 	TeamService
 	UserService
 	PullRequestService
+	IntegrationService
+	NotificationService
+	AdminService
+	StatsService
+	TokenService
+	AuditService
 }
 
 type TeamService interface {
-	CreateTeam(ctx context.Context, team *model.Team) (*model.Team, error)
+	CreateTeam(ctx context.Context, team *model.Team, conflictPolicy string) (*model.TeamCreation, error)
 	GetTeam(ctx context.Context, teamName string) (*model.Team, error)
+	ProvisionTeam(ctx context.Context, teamName string, userIDs []string) (*model.Team, error)
+	UpdateTeamMembers(ctx context.Context, teamName string, add, remove []string, reviewsPolicy string) (*model.Team, error)
+	DisbandTeam(ctx context.Context, teamName string) error
+	ListTeams(ctx context.Context, filter model.TeamListFilter) (*model.TeamList, error)
+	AddTeamMember(ctx context.Context, teamName string, member *model.TeamMember) (*model.Team, error)
+	RemoveTeamMember(ctx context.Context, teamName, userID, reviewsPolicy string) (*model.MembershipChange, error)
+	SetTeamParent(ctx context.Context, teamName, parentTeam string) (*model.Team, error)
+	SetTeamEscalation(ctx context.Context, teamName, escalation string) (*model.Team, error)
+	SetTeamReviewerRoles(ctx context.Context, teamName string, roles []string) (*model.Team, error)
+	SetTeamMentorship(ctx context.Context, teamName string, enabled bool) (*model.Team, error)
+	SetMemberRole(ctx context.Context, teamName, userID, role string) (*model.Team, error)
+	GetTeamTree(ctx context.Context, rootTeam string) ([]*model.TeamTreeNode, error)
+	RenameTeam(ctx context.Context, teamName, newName string) (*model.Team, error)
+	DeleteTeam(ctx context.Context, teamName, destinationTeam string, reparentSubTeams bool) error
+}
+
+// AdminService массовый импорт и экспорт команд и пользователей
+type AdminService interface {
+	ImportTeams(ctx context.Context, format string, data []byte, dryRun bool) (*model.ImportResult, error)
+	ExportTeams(ctx context.Context) ([]model.ImportTeam, error)
+}
+
+// StatsService отчёты по нагрузке ревьюеров и команд за период
+type StatsService interface {
+	GetReviewerStats(ctx context.Context, filter model.StatsFilter) ([]*model.ReviewerStats, error)
+	GetTeamStats(ctx context.Context, filter model.StatsFilter) ([]*model.TeamStats, error)
+}
+
+// TokenService выпуск, проверка и отзыв API-токенов
+type TokenService interface {
+	IssueAPIToken(ctx context.Context, userID, name string, scopes []string, expiresAt *time.Time) (*model.IssuedToken, error)
+	AuthenticateAPIToken(ctx context.Context, token string) (*model.APIToken, error)
+	GetAPIToken(ctx context.Context, tokenID int64) (*model.APIToken, error)
+	ListAPITokens(ctx context.Context, userID string) ([]*model.APIToken, error)
+	RevokeAPIToken(ctx context.Context, tokenID int64, ownerID string) error
+}
+
+// AuditService чтение журнала изменений
+type AuditService interface {
+	ListAuditLog(ctx context.Context, filter model.AuditFilter) (*model.AuditLog, error)
 }
 
 type UserService interface {
+	GetUser(ctx context.Context, userID string) (*model.User, error)
+	CreateUser(ctx context.Context, user *model.User) (*model.User, error)
+	ListUsers(ctx context.Context, filter model.UserFilter) (*model.UserList, error)
+	UpdateUser(ctx context.Context, update *model.UserUpdate) (*model.User, error)
 	SetUserActive(ctx context.Context, userID string, isActive bool) (*model.User, error)
+	SetUserAbsence(ctx context.Context, userID string, from, until *time.Time) (*model.User, error)
+	MoveUserToTeam(ctx context.Context, userID, teamName, reviewsPolicy string) (*model.MembershipChange, error)
 	GetUserReviewRequests(ctx context.Context, userID string) ([]*model.PullRequestShort, error)
 }
 
 type PullRequestService interface {
+	GetPullRequest(ctx context.Context, prID string) (*model.PullRequest, error)
 	CreatePullRequest(ctx context.Context, prID, prName, authorID string) (*model.PullRequest, error)
 	MergePullRequest(ctx context.Context, prID string) (*model.PullRequest, error)
+	ClosePullRequest(ctx context.Context, prID string) (*model.PullRequest, error)
+	ReopenPullRequest(ctx context.Context, prID string) (*model.PullRequest, error)
 	ReassignReviewer(ctx context.Context, prID string, oldUserID string) (*model.PullRequest, string, error)
-}
\ No newline at end of file
+	SubmitVerdict(ctx context.Context, prID, userID, verdict string) (*model.PullRequest, error)
+	AddShadowReviewer(ctx context.Context, prID, userID string) (*model.PullRequest, error)
+	RemoveShadowReviewer(ctx context.Context, prID, userID string) (*model.PullRequest, error)
+	GetPullRequestHistory(ctx context.Context, prID string) (*model.PullRequestHistory, error)
+	GetPullRequestAsOf(ctx context.Context, prID string, asOf time.Time) (*model.PullRequest, error)
+}
+
+type IntegrationService interface {
+	LinkUserIdentity(ctx context.Context, identity *model.UserIdentity) (*model.UserIdentity, error)
+	HandlePullRequestEvent(ctx context.Context, event *model.PullRequestEvent) (*model.WebhookResult, error)
+}
+
+type NotificationService interface {
+	SetNotificationPreference(ctx context.Context, update *model.NotificationPreferenceUpdate) (*model.NotificationPreference, error)
+	DeleteNotificationPreference(ctx context.Context, userID, channel string) error
+	GetNotificationPreferences(ctx context.Context, userID string) ([]*model.NotificationPreference, error)
+}
+
+// Notifier принимает события для асинхронной доставки уведомлений; Notify не должен блокировать вызывающего
+type Notifier interface {
+	Notify(event *model.NotificationEvent)
+}
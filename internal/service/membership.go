@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"review-service/internal/metrics"
+	"review-service/internal/model"
+	"review-service/internal/repository"
+)
+
+func (s *service) AddTeamMember(ctx context.Context, teamName string, member *model.TeamMember) (*model.Team, error) {
+	if teamName == "" || member.UserID == "" || member.Username == "" {
+		return nil, ErrInvalidInput
+	}
+	if member.Role != "" && !validRole(member.Role) {
+		return nil, NewBusinessError("INVALID_INPUT", "role must be lead, senior, member or trainee", nil)
+	}
+
+	exists, err := s.repo.TeamExists(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, NewBusinessError("NOT_FOUND", "team not found", nil)
+	}
+
+	if err := s.repo.AddTeamMember(ctx, teamName, member); err != nil {
+		if err == repository.ErrAlreadyTeamMember {
+			return nil, NewBusinessError("USER_EXISTS", "user is already a member of this team", err)
+		}
+		return nil, err
+	}
+
+	return s.repo.GetTeam(ctx, teamName)
+}
+
+func (s *service) RemoveTeamMember(ctx context.Context, teamName, userID, reviewsPolicy string) (*model.MembershipChange, error) {
+	if teamName == "" || userID == "" {
+		return nil, ErrInvalidInput
+	}
+
+	user, err := s.repo.GetUser(ctx, userID)
+	if err != nil {
+		if err == repository.ErrUserNotFound {
+			return nil, NewBusinessError("NOT_FOUND", "user not found", err)
+		}
+		return nil, err
+	}
+	if !contains(user.Teams, teamName) {
+		return nil, NewBusinessError("NOT_FOUND", "user is not a member of this team", ErrUserNotInTeam)
+	}
+
+	return s.changeUserTeam(ctx, user, teamName, "", reviewsPolicy)
+}
+
+func (s *service) MoveUserToTeam(ctx context.Context, userID, teamName, reviewsPolicy string) (*model.MembershipChange, error) {
+	if userID == "" || teamName == "" {
+		return nil, ErrInvalidInput
+	}
+
+	user, err := s.repo.GetUser(ctx, userID)
+	if err != nil {
+		if err == repository.ErrUserNotFound {
+			return nil, NewBusinessError("NOT_FOUND", "user not found", err)
+		}
+		return nil, err
+	}
+
+	exists, err := s.repo.TeamExists(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, NewBusinessError("NOT_FOUND", "team not found", nil)
+	}
+
+	if user.TeamName == teamName {
+		return &model.MembershipChange{User: user, ReviewsPolicy: reviewsPolicy, Replacements: []model.ReviewerReplacement{}}, nil
+	}
+
+	return s.changeUserTeam(ctx, user, user.TeamName, teamName, reviewsPolicy)
+}
+
+// changeUserTeam выводит пользователя из команды fromTeam (и при необходимости делает toTeam основной),
+// решая судьбу его открытых ревью в PR авторов из fromTeam по политике:
+// keep — ревью остаются за ним, reassign — заменяется активным участником fromTeam
+// (или снимается, если замены нет), reject — операция отклоняется при наличии таких ревью.
+func (s *service) changeUserTeam(ctx context.Context, user *model.User, fromTeam, toTeam, reviewsPolicy string) (*model.MembershipChange, error) {
+	change, err := s.applyTeamChange(ctx, user, fromTeam, toTeam, reviewsPolicy)
+	if err != nil {
+		return nil, err
+	}
+	s.finishTeamChange(ctx, change.Replacements)
+	return change, nil
+}
+
+// applyTeamChange — запись изменений changeUserTeam без уведомлений, чтобы несколько таких изменений
+// можно было выполнить в одной транзакции и разослать уведомления после её фиксации (finishTeamChange)
+func (s *service) applyTeamChange(ctx context.Context, user *model.User, fromTeam, toTeam, reviewsPolicy string) (*model.MembershipChange, error) {
+	if reviewsPolicy == "" {
+		reviewsPolicy = model.ReviewsPolicyReject
+	}
+
+	replacements := []model.ReviewerReplacement{}
+	switch reviewsPolicy {
+	case model.ReviewsPolicyKeep:
+	case model.ReviewsPolicyReassign, model.ReviewsPolicyReject:
+		openReviews, err := s.openReviews(ctx, user.UserID, fromTeam)
+		if err != nil {
+			return nil, err
+		}
+		if reviewsPolicy == model.ReviewsPolicyReject && len(openReviews) > 0 {
+			return nil, NewBusinessError("HAS_OPEN_REVIEWS", "user has open reviews, choose keep or reassign policy", nil)
+		}
+
+		for _, pr := range openReviews {
+			newReviewerID, err := s.selectReplacementReviewer(ctx, fromTeam, user.UserID, pr)
+			if err != nil && err != ErrNoReviewerCandidate {
+				return nil, err
+			}
+			replacements = append(replacements, model.ReviewerReplacement{
+				PullRequestID: pr.PullRequestID,
+				OldUserID:     user.UserID,
+				NewUserID:     newReviewerID,
+			})
+		}
+	default:
+		return nil, NewBusinessError("INVALID_INPUT", "reviews_policy must be keep, reassign or reject", nil)
+	}
+
+	updated, err := s.repo.ChangeUserTeam(ctx, user.UserID, fromTeam, toTeam, replacements)
+	if err != nil {
+		if err == repository.ErrUserNotInTeam {
+			return nil, NewBusinessError("NOT_FOUND", "user is not a member of this team", err)
+		}
+		return nil, err
+	}
+
+	return &model.MembershipChange{
+		User:          updated,
+		ReviewsPolicy: reviewsPolicy,
+		Replacements:  replacements,
+	}, nil
+}
+
+// openReviews возвращает открытые PR на ревью у пользователя, чьи авторы из команды teamName (по основной команде)
+func (s *service) openReviews(ctx context.Context, userID, teamName string) ([]*model.PullRequest, error) {
+	if teamName == "" {
+		return nil, nil
+	}
+
+	requests, err := s.repo.GetUserReviewRequests(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var prs []*model.PullRequest
+	for _, request := range requests {
+		if request.Status != "OPEN" {
+			continue
+		}
+		author, err := s.repo.GetUser(ctx, request.AuthorID)
+		if err != nil {
+			return nil, err
+		}
+		if author.TeamName != teamName {
+			continue
+		}
+		pr, err := s.repo.GetPullRequest(ctx, request.PullRequestID)
+		if err != nil {
+			return nil, err
+		}
+		// Теневое ревью остаётся за стажёром: заменять его не на кого
+		if !contains(pr.AssignedReviewers, userID) {
+			continue
+		}
+		prs = append(prs, pr)
+	}
+
+	return prs, nil
+}
+
+// finishTeamChange учитывает переназначения в метриках и рассылает уведомления о них
+func (s *service) finishTeamChange(ctx context.Context, replacements []model.ReviewerReplacement) {
+	metrics.Reassignments.WithLabelValues(metrics.ReasonTeamChange).Add(float64(len(replacements)))
+	s.notifyReplacements(ctx, replacements)
+}
+
+func (s *service) notifyReplacements(ctx context.Context, replacements []model.ReviewerReplacement) {
+	for _, replacement := range replacements {
+		if replacement.NewUserID == "" {
+			s.syncReviewers(ctx, replacement.PullRequestID, nil, []string{replacement.OldUserID})
+			continue
+		}
+		s.syncReviewers(ctx, replacement.PullRequestID, []string{replacement.NewUserID}, []string{replacement.OldUserID})
+
+		pr, err := s.repo.GetPullRequest(ctx, replacement.PullRequestID)
+		if err != nil {
+			continue
+		}
+		s.notifier.Notify(&model.NotificationEvent{
+			Type:          model.NotificationReassigned,
+			PullRequest:   pr,
+			RecipientIDs:  []string{replacement.OldUserID, replacement.NewUserID},
+			OldReviewerID: replacement.OldUserID,
+			NewReviewerID: replacement.NewUserID,
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"math/rand"
+	"review-service/internal/model"
+	"review-service/internal/repository"
+	"time"
+)
+
+func (s *service) SetTeamMentorship(ctx context.Context, teamName string, enabled bool) (*model.Team, error) {
+	if teamName == "" {
+		return nil, ErrInvalidInput
+	}
+
+	if err := s.repo.SetTeamMentorship(ctx, teamName, enabled); err != nil {
+		if err == repository.ErrTeamNotFound {
+			return nil, NewBusinessError("NOT_FOUND", "team not found", err)
+		}
+		return nil, err
+	}
+
+	return s.repo.GetTeam(ctx, teamName)
+}
+
+func (s *service) AddShadowReviewer(ctx context.Context, prID, userID string) (*model.PullRequest, error) {
+	if prID == "" || userID == "" {
+		return nil, ErrInvalidInput
+	}
+
+	user, err := s.repo.GetUser(ctx, userID)
+	if err != nil {
+		if err == repository.ErrUserNotFound {
+			return nil, NewBusinessError("NOT_FOUND", "user not found", err)
+		}
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, NewBusinessError("INVALID_INPUT", "user is not active", nil)
+	}
+
+	pr, err := s.repo.GetPullRequest(ctx, prID)
+	if err != nil {
+		if err == repository.ErrPRNotFound {
+			return nil, NewBusinessError("NOT_FOUND", "PR not found", err)
+		}
+		return nil, err
+	}
+	if pr.AuthorID == userID {
+		return nil, NewBusinessError("INVALID_INPUT", "author cannot shadow own PR", nil)
+	}
+
+	if err := s.repo.AddShadowReviewer(ctx, prID, userID); err != nil {
+		switch err {
+		case repository.ErrPRNotFound:
+			return nil, NewBusinessError("NOT_FOUND", "PR not found", err)
+		case repository.ErrPRMerged:
+			return nil, NewBusinessError("PR_MERGED", "cannot add shadow reviewer on merged PR", err)
+		case repository.ErrPRClosed:
+			return nil, NewBusinessError("PR_CLOSED", "cannot add shadow reviewer on closed PR", err)
+		case repository.ErrAlreadyReviewer:
+			return nil, NewBusinessError("USER_EXISTS", "user is already a reviewer of this PR", err)
+		}
+		return nil, err
+	}
+
+	updated, err := s.repo.GetPullRequest(ctx, prID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifier.Notify(&model.NotificationEvent{
+		Type:         model.NotificationAssigned,
+		PullRequest:  updated,
+		RecipientIDs: []string{userID},
+	})
+
+	return updated, nil
+}
+
+func (s *service) RemoveShadowReviewer(ctx context.Context, prID, userID string) (*model.PullRequest, error) {
+	if prID == "" || userID == "" {
+		return nil, ErrInvalidInput
+	}
+
+	if err := s.repo.RemoveShadowReviewer(ctx, prID, userID); err != nil {
+		if err == repository.ErrUserNotAssigned {
+			return nil, NewBusinessError("NOT_ASSIGNED", "user is not a shadow reviewer of this PR", err)
+		}
+		return nil, err
+	}
+
+	pr, err := s.repo.GetPullRequest(ctx, prID)
+	if err != nil {
+		if err == repository.ErrPRNotFound {
+			return nil, NewBusinessError("NOT_FOUND", "PR not found", err)
+		}
+		return nil, err
+	}
+
+	return pr, nil
+}
+
+// mentorshipCandidates убирает стажёров из кандидатов в обычные ревьюеры и ставит senior
+// первым в требуемые роли, чтобы стажёру было с кем работать в паре
+func mentorshipCandidates(team *model.Team) ([]model.TeamMember, []string) {
+	var members []model.TeamMember
+	for _, member := range team.Members {
+		if member.Role != model.RoleTrainee {
+			members = append(members, member)
+		}
+	}
+
+	roles := []string{model.RoleSenior}
+	for _, role := range team.RequiredReviewerRoles {
+		if role != model.RoleSenior && role != model.RoleTrainee {
+			roles = append(roles, role)
+		}
+	}
+
+	return members, roles
+}
+
+// selectShadowReviewer выбирает случайного активного и не отсутствующего стажёра команды, если среди ревьюеров
+// есть senior или lead этой команды; иначе возвращает пустую строку
+func selectShadowReviewer(team *model.Team, authorID string, reviewers []string) string {
+	mentored := false
+	now := time.Now()
+	var trainees []string
+	for _, member := range team.Members {
+		switch {
+		case (member.Role == model.RoleSenior || member.Role == model.RoleLead) && contains(reviewers, member.UserID):
+			mentored = true
+		case member.Role == model.RoleTrainee && member.Available(now) && member.UserID != authorID:
+			trainees = append(trainees, member.UserID)
+		}
+	}
+
+	if !mentored || len(trainees) == 0 {
+		return ""
+	}
+	return trainees[rand.Intn(len(trainees))]
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"net"
+	"net/mail"
+	"net/url"
+	"review-service/internal/model"
+	"review-service/internal/notification"
+)
+
+func (s *service) SetNotificationPreference(ctx context.Context, update *model.NotificationPreferenceUpdate) (*model.NotificationPreference, error) {
+	if update.UserID == "" || update.Target == "" {
+		return nil, ErrInvalidInput
+	}
+
+	target := update.Target
+	switch update.Channel {
+	case model.NotificationChannelSlack, model.NotificationChannelWebhook:
+		if err := validateWebhookTarget(target); err != nil {
+			return nil, err
+		}
+	case model.NotificationChannelEmail:
+		addr, err := mail.ParseAddress(target)
+		if err != nil {
+			return nil, NewBusinessError("INVALID_INPUT", "target must be an email address", err)
+		}
+		// "Имя <a@b>" пригодно для заголовка, но не для RCPT TO — храним только адрес
+		target = addr.Address
+	default:
+		return nil, NewBusinessError("INVALID_INPUT", "unknown notification channel", nil)
+	}
+
+	exists, err := s.repo.UserExists(ctx, update.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, NewBusinessError("NOT_FOUND", "user not found", nil)
+	}
+
+	current, err := s.repo.GetNotificationPreferences(ctx, []string{update.UserID})
+	if err != nil {
+		return nil, err
+	}
+	// новая подписка по умолчанию получает события, но не дайджест; в существующей
+	// меняются только переданные флаги
+	pref := &model.NotificationPreference{OnAssigned: true, OnReassigned: true, OnMerged: true}
+	for _, existing := range current {
+		if existing.Channel == update.Channel {
+			pref = existing
+		}
+	}
+
+	pref.UserID, pref.Channel, pref.Target = update.UserID, update.Channel, target
+	if update.OnAssigned != nil {
+		pref.OnAssigned = *update.OnAssigned
+	}
+	if update.OnReassigned != nil {
+		pref.OnReassigned = *update.OnReassigned
+	}
+	if update.OnMerged != nil {
+		pref.OnMerged = *update.OnMerged
+	}
+	if update.DailyDigest != nil {
+		pref.DailyDigest = *update.DailyDigest
+	}
+
+	if err := s.repo.SetNotificationPreference(ctx, pref); err != nil {
+		return nil, err
+	}
+
+	return pref, nil
+}
+
+// validateWebhookTarget принимает только https-URL. Адреса во внутренней сети отсекаются
+// ещё и при отправке (notification.NewSlackChannel), здесь — чтобы сразу сообщить об ошибке.
+func validateWebhookTarget(target string) error {
+	u, err := url.Parse(target)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" {
+		return NewBusinessError("INVALID_INPUT", "target must be an https URL", err)
+	}
+	if u.Hostname() == "localhost" {
+		return NewBusinessError("INVALID_INPUT", "target must be a public address", nil)
+	}
+	if ip := net.ParseIP(u.Hostname()); ip != nil && !notification.PublicIP(ip) {
+		return NewBusinessError("INVALID_INPUT", "target must be a public address", nil)
+	}
+	return nil
+}
+
+func (s *service) DeleteNotificationPreference(ctx context.Context, userID, channel string) error {
+	if userID == "" || channel == "" {
+		return ErrInvalidInput
+	}
+
+	return s.repo.DeleteNotificationPreference(ctx, userID, channel)
+}
+
+func (s *service) GetNotificationPreferences(ctx context.Context, userID string) ([]*model.NotificationPreference, error) {
+	if userID == "" {
+		return nil, ErrInvalidInput
+	}
+
+	exists, err := s.repo.UserExists(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, NewBusinessError("NOT_FOUND", "user not found", nil)
+	}
+
+	return s.repo.GetNotificationPreferences(ctx, []string{userID})
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"review-service/internal/model"
+	"review-service/internal/repository"
+	"testing"
+)
+
+type preferenceRepo struct {
+	repository.Repository
+	prefs []*model.NotificationPreference
+}
+
+func (r *preferenceRepo) UserExists(ctx context.Context, userID string) (bool, error) {
+	return true, nil
+}
+
+func (r *preferenceRepo) GetNotificationPreferences(ctx context.Context, userIDs []string) ([]*model.NotificationPreference, error) {
+	var result []*model.NotificationPreference
+	for _, pref := range r.prefs {
+		copied := *pref
+		result = append(result, &copied)
+	}
+	return result, nil
+}
+
+func (r *preferenceRepo) SetNotificationPreference(ctx context.Context, pref *model.NotificationPreference) error {
+	for i, existing := range r.prefs {
+		if existing.UserID == pref.UserID && existing.Channel == pref.Channel {
+			r.prefs[i] = pref
+			return nil
+		}
+	}
+	r.prefs = append(r.prefs, pref)
+	return nil
+}
+
+func boolPtr(v bool) *bool { return &v }
+
+func TestSetNotificationPreferenceDefaults(t *testing.T) {
+	s := &service{repo: &preferenceRepo{}}
+
+	pref, err := s.SetNotificationPreference(context.Background(), &model.NotificationPreferenceUpdate{
+		UserID: "u1", Channel: model.NotificationChannelSlack, Target: "https://hooks.example.com/x",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pref.OnAssigned || !pref.OnReassigned || !pref.OnMerged || pref.DailyDigest {
+		t.Fatalf("unexpected defaults: %+v", pref)
+	}
+}
+
+func TestSetNotificationPreferenceKeepsOmittedFlags(t *testing.T) {
+	repo := &preferenceRepo{prefs: []*model.NotificationPreference{{
+		UserID: "u1", Channel: model.NotificationChannelSlack, Target: "https://hooks.example.com/x",
+		OnAssigned: false, OnReassigned: true, OnMerged: false, DailyDigest: true,
+	}}}
+	s := &service{repo: repo}
+
+	pref, err := s.SetNotificationPreference(context.Background(), &model.NotificationPreferenceUpdate{
+		UserID: "u1", Channel: model.NotificationChannelSlack, Target: "https://hooks.example.com/y",
+		OnMerged: boolPtr(true),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := model.NotificationPreference{
+		UserID: "u1", Channel: model.NotificationChannelSlack, Target: "https://hooks.example.com/y",
+		OnAssigned: false, OnReassigned: true, OnMerged: true, DailyDigest: true,
+	}
+	if *pref != want {
+		t.Fatalf("got %+v, want %+v", *pref, want)
+	}
+}
+
+func TestSetNotificationPreferenceRejectsInternalTargets(t *testing.T) {
+	s := &service{repo: &preferenceRepo{}}
+	for _, target := range []string{
+		"http://hooks.example.com/x",
+		"https://localhost/hook",
+		"https://127.0.0.1/hook",
+		"https://10.0.0.5/hook",
+		"https://169.254.169.254/latest/meta-data",
+		"https://[::1]/hook",
+	} {
+		_, err := s.SetNotificationPreference(context.Background(), &model.NotificationPreferenceUpdate{
+			UserID: "u1", Channel: model.NotificationChannelWebhook, Target: target,
+		})
+		var bErr BusinessError
+		if !errors.As(err, &bErr) || bErr.Code != "INVALID_INPUT" {
+			t.Errorf("%s: expected INVALID_INPUT, got %v", target, err)
+		}
+	}
+}
+
+func TestSetNotificationPreferenceStoresBareEmail(t *testing.T) {
+	repo := &preferenceRepo{}
+	s := &service{repo: repo}
+
+	pref, err := s.SetNotificationPreference(context.Background(), &model.NotificationPreferenceUpdate{
+		UserID: "u1", Channel: model.NotificationChannelEmail, Target: "Alice Example <alice@example.com>",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pref.Target != "alice@example.com" || repo.prefs[0].Target != "alice@example.com" {
+		t.Fatalf("expected bare address, got %q", pref.Target)
+	}
+}
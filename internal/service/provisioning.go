@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"review-service/internal/model"
+	"review-service/internal/repository"
+)
+
+// CreateUser заводит пользователя без команды; команды назначаются отдельно
+func (s *service) CreateUser(ctx context.Context, user *model.User) (*model.User, error) {
+	if user.UserID == "" || user.Username == "" {
+		return nil, ErrInvalidInput
+	}
+
+	if err := s.repo.CreateUser(ctx, &model.User{UserID: user.UserID, Username: user.Username, IsActive: user.IsActive}); err != nil {
+		if err == repository.ErrUserExists {
+			return nil, NewBusinessError("USER_EXISTS", "user already exists", err)
+		}
+		return nil, err
+	}
+
+	return s.repo.GetUser(ctx, user.UserID)
+}
+
+// ProvisionTeam создаёт команду из уже существующих пользователей (возможно, пустую).
+// Команда становится для них основной, как при /team/add с conflict_policy=move.
+func (s *service) ProvisionTeam(ctx context.Context, teamName string, userIDs []string) (*model.Team, error) {
+	if teamName == "" {
+		return nil, ErrInvalidInput
+	}
+
+	exists, err := s.repo.TeamExists(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, NewBusinessError("TEAM_EXISTS", "team already exists", nil)
+	}
+
+	team := &model.Team{TeamName: teamName, Members: []model.TeamMember{}}
+	for _, userID := range userIDs {
+		user, err := s.repo.GetUser(ctx, userID)
+		if err != nil {
+			if err == repository.ErrUserNotFound {
+				return nil, NewBusinessError("NOT_FOUND", "user "+userID+" not found", err)
+			}
+			return nil, err
+		}
+		team.Members = append(team.Members, model.TeamMember{UserID: user.UserID, Username: user.Username, IsActive: user.IsActive})
+	}
+
+	if _, err := s.repo.CreateTeam(ctx, team, model.ConflictPolicyMove); err != nil {
+		if err == repository.ErrTeamExists {
+			return nil, NewBusinessError("TEAM_EXISTS", "team already exists", err)
+		}
+		return nil, err
+	}
+
+	return s.repo.GetTeam(ctx, teamName)
+}
+
+// UpdateTeamMembers добавляет в команду пользователей add (команда становится для них основной, как /users/moveTeam)
+// и выводит из неё remove; открытые ревью обрабатываются по reviewsPolicy. Уже состоящие в команде в add
+// и не состоящие в remove пропускаются. Всё выполняется одной транзакцией: ошибка на любом пользователе
+// оставляет команду как была, уведомления о переназначениях рассылаются только после фиксации.
+func (s *service) UpdateTeamMembers(ctx context.Context, teamName string, add, remove []string, reviewsPolicy string) (*model.Team, error) {
+	if teamName == "" {
+		return nil, ErrInvalidInput
+	}
+
+	var team *model.Team
+	var replacements []model.ReviewerReplacement
+	err := s.repo.InTx(ctx, func(ctx context.Context) error {
+		current, err := s.repo.GetTeam(ctx, teamName)
+		if err != nil {
+			if err == repository.ErrTeamNotFound {
+				return NewBusinessError("NOT_FOUND", "team not found", err)
+			}
+			return err
+		}
+		members := make(map[string]bool, len(current.Members))
+		for _, member := range current.Members {
+			members[member.UserID] = true
+		}
+
+		for _, userID := range remove {
+			if !members[userID] {
+				continue
+			}
+			change, err := s.changeMembership(ctx, userID, teamName, "", reviewsPolicy)
+			if err != nil {
+				return err
+			}
+			replacements = append(replacements, change.Replacements...)
+			delete(members, userID)
+		}
+		for _, userID := range add {
+			if members[userID] {
+				continue
+			}
+			change, err := s.changeMembership(ctx, userID, "", teamName, reviewsPolicy)
+			if err != nil {
+				return err
+			}
+			replacements = append(replacements, change.Replacements...)
+			members[userID] = true
+		}
+
+		team, err = s.repo.GetTeam(ctx, teamName)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.finishTeamChange(ctx, replacements)
+	return team, nil
+}
+
+// DisbandTeam выводит из команды всех участников, переназначая их открытые ревью, и удаляет её —
+// одной транзакцией, как UpdateTeamMembers
+func (s *service) DisbandTeam(ctx context.Context, teamName string) error {
+	if teamName == "" {
+		return ErrInvalidInput
+	}
+
+	var replacements []model.ReviewerReplacement
+	err := s.repo.InTx(ctx, func(ctx context.Context) error {
+		team, err := s.repo.GetTeam(ctx, teamName)
+		if err != nil {
+			if err == repository.ErrTeamNotFound {
+				return NewBusinessError("NOT_FOUND", "team not found", err)
+			}
+			return err
+		}
+
+		for _, member := range team.Members {
+			change, err := s.changeMembership(ctx, member.UserID, teamName, "", model.ReviewsPolicyReassign)
+			if err != nil {
+				return err
+			}
+			replacements = append(replacements, change.Replacements...)
+		}
+
+		return s.DeleteTeam(ctx, teamName, "", false)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.finishTeamChange(ctx, replacements)
+	return nil
+}
+
+// changeMembership выводит пользователя из fromTeam или переводит в toTeam (из его основной команды)
+// без уведомлений — для использования внутри транзакции
+func (s *service) changeMembership(ctx context.Context, userID, fromTeam, toTeam, reviewsPolicy string) (*model.MembershipChange, error) {
+	user, err := s.repo.GetUser(ctx, userID)
+	if err != nil {
+		if err == repository.ErrUserNotFound {
+			return nil, NewBusinessError("NOT_FOUND", "user "+userID+" not found", err)
+		}
+		return nil, err
+	}
+	if toTeam != "" {
+		fromTeam = user.TeamName
+	}
+	return s.applyTeamChange(ctx, user, fromTeam, toTeam, reviewsPolicy)
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"review-service/internal/model"
+	"review-service/internal/repository"
+	"testing"
+)
+
+// membershipRepo дополняет reviewRepo сменой команд; InTx откатывает составы команд и PR при ошибке
+type membershipRepo struct {
+	*reviewRepo
+	createdUsers map[string]bool
+}
+
+func (r *membershipRepo) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	teams := map[string]*model.Team{}
+	for name, team := range r.teams {
+		copied := *team
+		copied.Members = append([]model.TeamMember{}, team.Members...)
+		teams[name] = &copied
+	}
+	prs := map[string]*model.PullRequest{}
+	for id, pr := range r.prs {
+		copied := *pr
+		copied.AssignedReviewers = append([]string{}, pr.AssignedReviewers...)
+		prs[id] = &copied
+	}
+	if err := fn(ctx); err != nil {
+		r.teams, r.prs = teams, prs
+		return err
+	}
+	return nil
+}
+
+func (r *membershipRepo) GetUserReviewRequests(ctx context.Context, userID string) ([]*model.PullRequestShort, error) {
+	var requests []*model.PullRequestShort
+	for _, pr := range r.prs {
+		if contains(pr.AssignedReviewers, userID) {
+			requests = append(requests, &model.PullRequestShort{PullRequestID: pr.PullRequestID, AuthorID: pr.AuthorID, Status: pr.Status})
+		}
+	}
+	return requests, nil
+}
+
+func (r *membershipRepo) ChangeUserTeam(ctx context.Context, userID string, fromTeam, toTeam string, replacements []model.ReviewerReplacement) (*model.User, error) {
+	if team, ok := r.teams[fromTeam]; ok {
+		var members []model.TeamMember
+		for _, m := range team.Members {
+			if m.UserID != userID {
+				members = append(members, m)
+			}
+		}
+		team.Members = members
+	}
+	if team, ok := r.teams[toTeam]; ok {
+		team.Members = append(team.Members, member(userID, "", true))
+	}
+	for _, replacement := range replacements {
+		pr := r.prs[replacement.PullRequestID]
+		var reviewers []string
+		for _, reviewer := range pr.AssignedReviewers {
+			switch {
+			case reviewer != userID:
+				reviewers = append(reviewers, reviewer)
+			case replacement.NewUserID != "":
+				reviewers = append(reviewers, replacement.NewUserID)
+			}
+		}
+		pr.AssignedReviewers = reviewers
+	}
+	return &model.User{UserID: userID, TeamName: toTeam}, nil
+}
+
+func (r *membershipRepo) DeleteTeam(ctx context.Context, teamName, destinationTeam string, reparentSubTeams bool) error {
+	if len(r.teams[teamName].Members) > 0 {
+		return repository.ErrDestinationRequired
+	}
+	delete(r.teams, teamName)
+	return nil
+}
+
+func (r *membershipRepo) CreateUser(ctx context.Context, user *model.User) error {
+	if r.createdUsers[user.UserID] {
+		return repository.ErrUserExists
+	}
+	r.createdUsers[user.UserID] = true
+	return nil
+}
+
+// recordingNotifier запоминает разосланные события
+type recordingNotifier struct {
+	events []*model.NotificationEvent
+}
+
+func (n *recordingNotifier) Notify(event *model.NotificationEvent) {
+	n.events = append(n.events, event)
+}
+
+func newMembershipRepo() *membershipRepo {
+	repo := &membershipRepo{
+		reviewRepo: newReviewRepo(
+			&model.Team{TeamName: "backend", Members: []model.TeamMember{
+				member("author", model.RoleMember, true), member("alice", model.RoleMember, true), member("bob", model.RoleMember, true),
+			}},
+			&model.Team{TeamName: "ops", Members: []model.TeamMember{member("carol", model.RoleMember, true)}},
+		),
+		createdUsers: map[string]bool{},
+	}
+	repo.prs["pr-1"] = &model.PullRequest{
+		PullRequestID: "pr-1", AuthorID: "author", Status: "OPEN", AssignedReviewers: []string{"alice"},
+	}
+	return repo
+}
+
+func teamMembers(team *model.Team) []string {
+	var ids []string
+	for _, m := range team.Members {
+		ids = append(ids, m.UserID)
+	}
+	return ids
+}
+
+func TestUpdateTeamMembers(t *testing.T) {
+	repo := newMembershipRepo()
+	notifier := &recordingNotifier{}
+	s := &service{repo: repo, notifier: notifier}
+
+	team, err := s.UpdateTeamMembers(context.Background(), "backend", []string{"carol"}, []string{"alice"}, model.ReviewsPolicyReassign)
+	if err != nil {
+		t.Fatal(err)
+	}
+	members := teamMembers(team)
+	if contains(members, "alice") || !contains(members, "carol") {
+		t.Fatalf("unexpected members %v", members)
+	}
+	if len(notifier.events) != 1 || notifier.events[0].OldReviewerID != "alice" {
+		t.Fatalf("expected one reassignment notification, got %+v", notifier.events)
+	}
+}
+
+func TestUpdateTeamMembersIsAtomic(t *testing.T) {
+	repo := newMembershipRepo()
+	notifier := &recordingNotifier{}
+	s := &service{repo: repo, notifier: notifier}
+
+	_, err := s.UpdateTeamMembers(context.Background(), "backend", []string{"carol", "ghost"}, []string{"alice"}, model.ReviewsPolicyReassign)
+	bErr, ok := err.(BusinessError)
+	if !ok || bErr.Code != "NOT_FOUND" {
+		t.Fatalf("expected NOT_FOUND, got %v", err)
+	}
+
+	members := teamMembers(repo.teams["backend"])
+	if !contains(members, "alice") || contains(members, "carol") {
+		t.Fatalf("partial change left behind: %v", members)
+	}
+	if reviewers := repo.prs["pr-1"].AssignedReviewers; len(reviewers) != 1 || reviewers[0] != "alice" {
+		t.Fatalf("review reassigned despite rollback: %v", reviewers)
+	}
+	if len(notifier.events) != 0 {
+		t.Fatalf("notifications sent for a rolled back change: %+v", notifier.events)
+	}
+}
+
+func TestDisbandTeam(t *testing.T) {
+	repo := newMembershipRepo()
+	s := &service{repo: repo, notifier: &recordingNotifier{}}
+
+	if err := s.DisbandTeam(context.Background(), "ops"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := repo.teams["ops"]; ok {
+		t.Fatal("team was not deleted")
+	}
+}
+
+func TestCreateUserExists(t *testing.T) {
+	repo := newMembershipRepo()
+	repo.createdUsers["dave"] = true
+	s := &service{repo: repo, notifier: discardNotifier{}}
+
+	_, err := s.CreateUser(context.Background(), &model.User{UserID: "dave", Username: "Dave", IsActive: true})
+	bErr, ok := err.(BusinessError)
+	if !ok || bErr.Code != "USER_EXISTS" {
+		t.Fatalf("expected USER_EXISTS, got %v", err)
+	}
+}
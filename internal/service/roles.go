@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"review-service/internal/model"
+	"review-service/internal/repository"
+)
+
+func (s *service) SetMemberRole(ctx context.Context, teamName, userID, role string) (*model.Team, error) {
+	if teamName == "" || userID == "" {
+		return nil, ErrInvalidInput
+	}
+	if !validRole(role) {
+		return nil, NewBusinessError("INVALID_INPUT", "role must be lead, senior, member or trainee", nil)
+	}
+
+	if err := s.repo.SetMemberRole(ctx, teamName, userID, role); err != nil {
+		if err == repository.ErrUserNotInTeam {
+			return nil, NewBusinessError("NOT_FOUND", "user is not a member of this team", err)
+		}
+		return nil, err
+	}
+
+	return s.repo.GetTeam(ctx, teamName)
+}
+
+// SetTeamReviewerRoles задаёт роли, представитель каждой из которых назначается ревьюером на PR команды
+func (s *service) SetTeamReviewerRoles(ctx context.Context, teamName string, roles []string) (*model.Team, error) {
+	if teamName == "" {
+		return nil, ErrInvalidInput
+	}
+	roles, err := normalizeReviewerRoles(roles)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetTeamReviewerRoles(ctx, teamName, roles); err != nil {
+		if err == repository.ErrTeamNotFound {
+			return nil, NewBusinessError("NOT_FOUND", "team not found", err)
+		}
+		return nil, err
+	}
+
+	return s.repo.GetTeam(ctx, teamName)
+}
+
+// normalizeReviewerRoles проверяет роли и убирает повторы. Требований не может быть больше,
+// чем ревьюеров на PR, иначе часть из них никогда не выполнится.
+func normalizeReviewerRoles(roles []string) ([]string, error) {
+	normalized := []string{}
+	for _, role := range roles {
+		if !validRole(role) {
+			return nil, NewBusinessError("INVALID_INPUT", "required reviewer roles must be lead, senior, member or trainee", nil)
+		}
+		if !contains(normalized, role) {
+			normalized = append(normalized, role)
+		}
+	}
+	if len(normalized) > maxReviewers {
+		return nil, NewBusinessError("INVALID_INPUT", "too many required reviewer roles", nil)
+	}
+	return normalized, nil
+}
+
+func validRole(role string) bool {
+	switch role {
+	case model.RoleLead, model.RoleSenior, model.RoleMember, model.RoleTrainee:
+		return true
+	}
+	return false
+}
@@ -3,24 +3,62 @@ package service
 import (
 	"context"
 	"math/rand"
+	"review-service/internal/metrics"
 	"review-service/internal/model"
 	"review-service/internal/repository"
+	"strings"
 	"time"
 )
 
+const maxReviewers = 2
+
 type service struct {
-	repo repository.Repository
+	repo     repository.Repository
+	notifier Notifier
 }
 
-func NewService(repo repository.Repository) Service {
-	return &service{repo: repo}
+func NewService(repo repository.Repository, notifier Notifier) Service {
+	return &tracedService{next: &service{repo: repo, notifier: notifier}}
 }
 
-func (s *service) CreateTeam(ctx context.Context, team *model.Team) (*model.Team, error) {
+func (s *service) CreateTeam(ctx context.Context, team *model.Team, conflictPolicy string) (*model.TeamCreation, error) {
 	if team.TeamName == "" || len(team.Members) == 0 {
 		return nil, ErrInvalidInput
 	}
 
+	if conflictPolicy == "" {
+		conflictPolicy = model.ConflictPolicyReject
+	}
+	switch conflictPolicy {
+	case model.ConflictPolicyReject, model.ConflictPolicyMove, model.ConflictPolicySkip, model.ConflictPolicyJoin:
+	default:
+		return nil, NewBusinessError("INVALID_INPUT", "conflict_policy must be reject, move, join or skip", nil)
+	}
+
+	for _, member := range team.Members {
+		if member.Role != "" && !validRole(member.Role) {
+			return nil, NewBusinessError("INVALID_INPUT", "role must be lead, senior, member or trainee", nil)
+		}
+	}
+	roles, err := normalizeReviewerRoles(team.RequiredReviewerRoles)
+	if err != nil {
+		return nil, err
+	}
+	team.RequiredReviewerRoles = roles
+
+	if team.Escalation != "" && !validEscalation(team.Escalation) {
+		return nil, NewBusinessError("INVALID_INPUT", "escalation must be none, siblings, parent or siblings_then_parent", nil)
+	}
+	if team.ParentTeam != "" {
+		exists, err := s.repo.TeamExists(ctx, team.ParentTeam)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, NewBusinessError("NOT_FOUND", "parent team not found", nil)
+		}
+	}
+
 	exists, err := s.repo.TeamExists(ctx, team.TeamName)
 	if err != nil {
 		return nil, err
@@ -29,11 +67,29 @@ func (s *service) CreateTeam(ctx context.Context, team *model.Team) (*model.Team
 		return nil, NewBusinessError("TEAM_EXISTS", "team already exists", nil)
 	}
 
-	if err := s.repo.CreateTeam(ctx, team); err != nil {
+	outcomes, err := s.repo.CreateTeam(ctx, team, conflictPolicy)
+	if err != nil {
+		switch err {
+		case repository.ErrTeamExists:
+			return nil, NewBusinessError("TEAM_EXISTS", "team already exists", err)
+		case repository.ErrUserInOtherTeam:
+			var conflicting []string
+			for _, outcome := range outcomes {
+				if outcome.Outcome == model.MemberConflict {
+					conflicting = append(conflicting, outcome.UserID+" ("+outcome.PreviousTeam+")")
+				}
+			}
+			return nil, NewBusinessError("USER_IN_OTHER_TEAM", "users already belong to other teams: "+strings.Join(conflicting, ", "), err)
+		}
 		return nil, err
 	}
 
-	return team, nil
+	created, err := s.repo.GetTeam(ctx, team.TeamName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TeamCreation{Team: created, Members: outcomes}, nil
 }
 
 func (s *service) GetTeam(ctx context.Context, teamName string) (*model.Team, error) {
@@ -68,6 +124,33 @@ func (s *service) SetUserActive(ctx context.Context, userID string, isActive boo
 	return user, nil
 }
 
+// SetUserAbsence задаёт период отсутствия [from, until), в который пользователь не выбирается ревьюером
+// (уже назначенные ревью за ним остаются). Без from отсутствие начинается сразу, без until — снимается.
+func (s *service) SetUserAbsence(ctx context.Context, userID string, from, until *time.Time) (*model.User, error) {
+	if userID == "" {
+		return nil, ErrInvalidInput
+	}
+	if until == nil {
+		from = nil
+	} else if from == nil {
+		now := time.Now()
+		from = &now
+	}
+	if from != nil && !from.Before(*until) {
+		return nil, NewBusinessError("INVALID_INPUT", "absent_until must be after absent_from", nil)
+	}
+
+	user, err := s.repo.SetUserAbsence(ctx, userID, from, until)
+	if err != nil {
+		if err == repository.ErrUserNotFound {
+			return nil, NewBusinessError("NOT_FOUND", "user not found", err)
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
 func (s *service) GetUserReviewRequests(ctx context.Context, userID string) ([]*model.PullRequestShort, error) {
 	if userID == "" {
 		return nil, ErrInvalidInput
@@ -85,6 +168,18 @@ func (s *service) GetUserReviewRequests(ctx context.Context, userID string) ([]*
 }
 
 func (s *service) CreatePullRequest(ctx context.Context, prID, prName, authorID string) (*model.PullRequest, error) {
+	pr, err := s.createPullRequest(ctx, prID, prName, authorID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.finishPullRequestCreate(pr)
+	return pr, nil
+}
+
+// createPullRequest создаёт PR и назначает ревьюеров без метрик и уведомлений — для использования
+// внутри транзакции; после фиксации нужно вызвать finishPullRequestCreate
+func (s *service) createPullRequest(ctx context.Context, prID, prName, authorID string) (*model.PullRequest, error) {
 	if prID == "" || prName == "" || authorID == "" {
 		return nil, ErrInvalidInput
 	}
@@ -105,7 +200,25 @@ func (s *service) CreatePullRequest(ctx context.Context, prID, prName, authorID
 		return nil, err
 	}
 
-	reviewers := s.selectReviewers(team.Members, authorID)
+	candidates, requiredRoles := team.Members, team.RequiredReviewerRoles
+	if team.Mentorship {
+		candidates, requiredRoles = mentorshipCandidates(team)
+	}
+
+	reviewers := s.selectReviewers(candidates, []string{authorID}, maxReviewers, requiredRoles)
+	if len(reviewers) < maxReviewers {
+		reviewers, err = s.escalateReviewers(ctx, team, authorID, reviewers)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var shadowReviewers []string
+	if team.Mentorship {
+		if shadowID := selectShadowReviewer(team, authorID, reviewers); shadowID != "" {
+			shadowReviewers = append(shadowReviewers, shadowID)
+		}
+	}
 
 	now := time.Now()
 	pr := &model.PullRequest{
@@ -114,6 +227,7 @@ func (s *service) CreatePullRequest(ctx context.Context, prID, prName, authorID
 		AuthorID:         authorID,
 		Status:           "OPEN",
 		AssignedReviewers: reviewers,
+		ShadowReviewers:  shadowReviewers,
 		CreatedAt:        &now,
 	}
 
@@ -127,12 +241,25 @@ func (s *service) CreatePullRequest(ctx context.Context, prID, prName, authorID
 	return pr, nil
 }
 
-func (s *service) MergePullRequest(ctx context.Context, prID string) (*model.PullRequest, error) {
+// finishPullRequestCreate учитывает созданный PR в метриках и уведомляет назначенных ревьюеров
+func (s *service) finishPullRequestCreate(pr *model.PullRequest) {
+	metrics.PullRequestsCreated.Inc()
+
+	if recipients := append(append([]string{}, pr.AssignedReviewers...), pr.ShadowReviewers...); len(recipients) > 0 {
+		s.notifier.Notify(&model.NotificationEvent{
+			Type:         model.NotificationAssigned,
+			PullRequest:  pr,
+			RecipientIDs: recipients,
+		})
+	}
+}
+
+func (s *service) GetPullRequest(ctx context.Context, prID string) (*model.PullRequest, error) {
 	if prID == "" {
 		return nil, ErrInvalidInput
 	}
 
-	_, err := s.repo.GetPullRequest(ctx, prID)
+	pr, err := s.repo.GetPullRequest(ctx, prID)
 	if err != nil {
 		if err == repository.ErrPRNotFound {
 			return nil, NewBusinessError("NOT_FOUND", "PR not found", err)
@@ -140,7 +267,95 @@ func (s *service) MergePullRequest(ctx context.Context, prID string) (*model.Pul
 		return nil, err
 	}
 
+	return pr, nil
+}
+
+func (s *service) MergePullRequest(ctx context.Context, prID string) (*model.PullRequest, error) {
+	merged, justMerged, err := s.mergePullRequest(ctx, prID)
+	if err != nil {
+		return nil, err
+	}
+
+	if justMerged {
+		s.finishPullRequestMerge(merged)
+	}
+	return merged, nil
+}
+
+// mergePullRequest сливает PR без метрик и уведомлений — для использования внутри транзакции.
+// justMerged сообщает, что PR был открыт и слит сейчас: тогда после фиксации нужно вызвать finishPullRequestMerge.
+func (s *service) mergePullRequest(ctx context.Context, prID string) (merged *model.PullRequest, justMerged bool, err error) {
+	if prID == "" {
+		return nil, false, ErrInvalidInput
+	}
+
+	pr, err := s.repo.GetPullRequest(ctx, prID)
+	if err != nil {
+		if err == repository.ErrPRNotFound {
+			return nil, false, NewBusinessError("NOT_FOUND", "PR not found", err)
+		}
+		return nil, false, err
+	}
+
+	if pr.Status == "CLOSED" {
+		return nil, false, NewBusinessError("PR_CLOSED", "cannot merge closed PR", nil)
+	}
+
 	if err := s.repo.MergePullRequest(ctx, prID); err != nil {
+		return nil, false, err
+	}
+
+	merged, err = s.repo.GetPullRequest(ctx, prID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return merged, pr.Status == "OPEN", nil
+}
+
+// finishPullRequestMerge учитывает слияние в метриках и уведомляет ревьюеров
+func (s *service) finishPullRequestMerge(merged *model.PullRequest) {
+	metrics.PullRequestsMerged.Inc()
+
+	if len(merged.AssignedReviewers) > 0 {
+		s.notifier.Notify(&model.NotificationEvent{
+			Type:         model.NotificationMerged,
+			PullRequest:  merged,
+			RecipientIDs: merged.AssignedReviewers,
+		})
+	}
+}
+
+func (s *service) ClosePullRequest(ctx context.Context, prID string) (*model.PullRequest, error) {
+	if prID == "" {
+		return nil, ErrInvalidInput
+	}
+
+	if err := s.repo.ClosePullRequest(ctx, prID); err != nil {
+		switch err {
+		case repository.ErrPRNotFound:
+			return nil, NewBusinessError("NOT_FOUND", "PR not found", err)
+		case repository.ErrPRMerged:
+			return nil, NewBusinessError("PR_MERGED", "cannot close merged PR", err)
+		}
+		return nil, err
+	}
+
+	return s.repo.GetPullRequest(ctx, prID)
+}
+
+func (s *service) ReopenPullRequest(ctx context.Context, prID string) (*model.PullRequest, error) {
+	if prID == "" {
+		return nil, ErrInvalidInput
+	}
+
+	if err := s.repo.ReopenPullRequest(ctx, prID); err != nil {
+		switch err {
+		case repository.ErrPRNotFound:
+			return nil, NewBusinessError("NOT_FOUND", "PR not found", err)
+		case repository.ErrPRMerged:
+			return nil, NewBusinessError("PR_MERGED", "cannot reopen merged PR", err)
+		}
 		return nil, err
 	}
 
@@ -163,6 +378,9 @@ func (s *service) ReassignReviewer(ctx context.Context, prID string, oldUserID s
 	if pr.Status == "MERGED" {
 		return nil, "", NewBusinessError("PR_MERGED", "cannot reassign on merged PR", nil)
 	}
+	if pr.Status == "CLOSED" {
+		return nil, "", NewBusinessError("PR_CLOSED", "cannot reassign on closed PR", nil)
+	}
 
 	assigned, err := s.repo.IsUserAssignedToPR(ctx, prID, oldUserID)
 	if err != nil {
@@ -172,58 +390,139 @@ func (s *service) ReassignReviewer(ctx context.Context, prID string, oldUserID s
 		return nil, "", NewBusinessError("NOT_ASSIGNED", "reviewer is not assigned to this PR", nil)
 	}
 
-	oldReviewer, err := s.repo.GetUser(ctx, oldUserID)
+	// замену ищем там же, где CreatePullRequest искал ревьюеров, — в команде автора,
+	// а не в команде уходящего ревьюера (он мог быть добран эскалацией из соседней)
+	author, err := s.repo.GetUser(ctx, pr.AuthorID)
 	if err != nil {
 		return nil, "", err
 	}
 
-	newReviewerID, err := s.selectReplacementReviewer(ctx, oldReviewer.TeamName, oldUserID, pr.AssignedReviewers)
+	newReviewerID, err := s.selectReplacementReviewer(ctx, author.TeamName, oldUserID, pr)
 	if err != nil {
+		metrics.NoCandidate.Inc()
 		return nil, "", NewBusinessError("NO_CANDIDATE", "no active replacement candidate in team", err)
 	}
 
 	if err := s.repo.ReassignReviewer(ctx, prID, oldUserID, newReviewerID); err != nil {
 		return nil, "", err
 	}
+	metrics.Reassignments.WithLabelValues(metrics.ReasonManual).Inc()
+	s.syncReviewers(ctx, prID, []string{newReviewerID}, []string{oldUserID})
 
 	updatedPR, err := s.repo.GetPullRequest(ctx, prID)
 	if err != nil {
 		return nil, "", err
 	}
 
+	s.notifier.Notify(&model.NotificationEvent{
+		Type:          model.NotificationReassigned,
+		PullRequest:   updatedPR,
+		RecipientIDs:  []string{oldUserID, newReviewerID},
+		OldReviewerID: oldUserID,
+		NewReviewerID: newReviewerID,
+	})
+
 	return updatedPR, newReviewerID, nil
 }
 
-func (s *service) selectReviewers(members []model.TeamMember, excludeUserID string) []string {
-	var candidateIDs []string
+// selectReviewers выбирает до limit активных и не отсутствующих участников. Сначала для каждой роли из requiredRoles
+// берётся случайный участник с этой ролью (если такой есть), оставшиеся места заполняются случайно.
+func (s *service) selectReviewers(members []model.TeamMember, excludeUserIDs []string, limit int, requiredRoles []string) []string {
+	var candidates []model.TeamMember
+	now := time.Now()
 	
 	for _, member := range members {
-		if member.IsActive && member.UserID != excludeUserID {
-			candidateIDs = append(candidateIDs, member.UserID)
+		if member.Available(now) && !contains(excludeUserIDs, member.UserID) {
+			candidates = append(candidates, member)
 		}
 	}
 
 	rand.New(rand.NewSource(time.Now().UnixNano()))
-	rand.Shuffle(len(candidateIDs), func(i, j int) {
-		candidateIDs[i], candidateIDs[j] = candidateIDs[j], candidateIDs[i]
+	rand.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
 	})
 
-	if len(candidateIDs) > 2 {
-		return candidateIDs[:2]
+	var reviewerIDs []string
+	for _, role := range requiredRoles {
+		for _, candidate := range candidates {
+			if len(reviewerIDs) < limit && candidate.Role == role && !contains(reviewerIDs, candidate.UserID) {
+				reviewerIDs = append(reviewerIDs, candidate.UserID)
+				break
+			}
+		}
 	}
-	return candidateIDs
+
+	for _, candidate := range candidates {
+		if len(reviewerIDs) >= limit {
+			break
+		}
+		if !contains(reviewerIDs, candidate.UserID) {
+			reviewerIDs = append(reviewerIDs, candidate.UserID)
+		}
+	}
+
+	return reviewerIDs
 }
 
-func (s *service) selectReplacementReviewer(ctx context.Context, teamName string, excludeUserID string, currentReviewers []string) (string, error) {
-	candidates, err := s.repo.GetActiveUsersByTeam(ctx, teamName, excludeUserID)
+// escalateReviewers добирает ревьюеров из соседних или родительской команды согласно team.Escalation
+func (s *service) escalateReviewers(ctx context.Context, team *model.Team, authorID string, reviewers []string) ([]string, error) {
+	teamNames, err := s.escalationTeams(ctx, team)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, teamName := range teamNames {
+		if len(reviewers) >= maxReviewers {
+			break
+		}
+
+		escalated, err := s.repo.GetTeam(ctx, teamName)
+		if err != nil {
+			return nil, err
+		}
+		exclude := append([]string{authorID}, reviewers...)
+		reviewers = append(reviewers, s.selectReviewers(escalated.Members, exclude, maxReviewers-len(reviewers), nil)...)
+	}
+
+	return reviewers, nil
+}
+
+// selectReplacementReviewer выбирает замену ревьюеру oldUserID в PR по тем же правилам, что и CreatePullRequest:
+// кандидаты — активные участники команды teamName (в команде с наставничеством без стажёров), затем команды эскалации.
+// Если уходящий ревьюер закрывал обязательную роль, которую не закрывает никто из оставшихся, замена должна
+// иметь ту же роль; без подходящего кандидата возвращается ErrNoReviewerCandidate, а не ревьюер без роли.
+func (s *service) selectReplacementReviewer(ctx context.Context, teamName string, oldUserID string, pr *model.PullRequest) (string, error) {
+	team, err := s.repo.GetTeam(ctx, teamName)
+	if err == repository.ErrTeamNotFound {
+		return "", ErrNoReviewerCandidate
+	}
 	if err != nil {
 		return "", err
 	}
 
-	var availableCandidates []string
-	for _, candidate := range candidates {
-		if !contains(currentReviewers, candidate.UserID) {
-			availableCandidates = append(availableCandidates, candidate.UserID)
+	candidates, requiredRoles := team.Members, team.RequiredReviewerRoles
+	if team.Mentorship {
+		candidates, requiredRoles = mentorshipCandidates(team)
+	}
+	requiredRole := uncoveredRole(team, requiredRoles, oldUserID, pr.AssignedReviewers)
+
+	exclude := append(append([]string{pr.AuthorID, oldUserID}, pr.AssignedReviewers...), pr.ShadowReviewers...)
+	availableCandidates := replacementCandidates(candidates, exclude, requiredRole)
+
+	if len(availableCandidates) == 0 {
+		teamNames, err := s.escalationTeams(ctx, team)
+		if err != nil {
+			return "", err
+		}
+		for _, teamName := range teamNames {
+			escalated, err := s.repo.GetTeam(ctx, teamName)
+			if err != nil {
+				return "", err
+			}
+			availableCandidates = replacementCandidates(escalated.Members, exclude, requiredRole)
+			if len(availableCandidates) > 0 {
+				break
+			}
 		}
 	}
 
@@ -235,6 +534,42 @@ func (s *service) selectReplacementReviewer(ctx context.Context, teamName string
 	return availableCandidates[rand.Intn(len(availableCandidates))], nil
 }
 
+// uncoveredRole возвращает обязательную роль, которую в команде закрывал ревьюер oldUserID и не закрывает
+// больше никто из остальных ревьюеров PR, или пустую строку
+func uncoveredRole(team *model.Team, requiredRoles []string, oldUserID string, reviewers []string) string {
+	roles := make(map[string]string, len(team.Members))
+	for _, member := range team.Members {
+		roles[member.UserID] = member.Role
+	}
+
+	oldRole := roles[oldUserID]
+	if oldRole == "" || !contains(requiredRoles, oldRole) {
+		return ""
+	}
+	for _, reviewer := range reviewers {
+		if reviewer != oldUserID && roles[reviewer] == oldRole {
+			return ""
+		}
+	}
+	return oldRole
+}
+
+// replacementCandidates возвращает активных и не отсутствующих участников не из exclude, а при непустой role — только с этой ролью
+func replacementCandidates(members []model.TeamMember, exclude []string, role string) []string {
+	var candidates []string
+	now := time.Now()
+	for _, member := range members {
+		if !member.Available(now) || contains(exclude, member.UserID) || contains(candidates, member.UserID) {
+			continue
+		}
+		if role != "" && member.Role != role {
+			continue
+		}
+		candidates = append(candidates, member.UserID)
+	}
+	return candidates
+}
+
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
 		if s == item {
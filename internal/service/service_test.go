@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"review-service/internal/model"
+	"review-service/internal/repository"
+	"testing"
+)
+
+// reviewRepo — команды и PR в памяти для проверки выбора ревьюеров
+type reviewRepo struct {
+	repository.Repository
+	teams map[string]*model.Team
+	prs   map[string]*model.PullRequest
+}
+
+func newReviewRepo(teams ...*model.Team) *reviewRepo {
+	r := &reviewRepo{teams: map[string]*model.Team{}, prs: map[string]*model.PullRequest{}}
+	for _, team := range teams {
+		r.teams[team.TeamName] = team
+	}
+	return r
+}
+
+func (r *reviewRepo) GetUser(ctx context.Context, userID string) (*model.User, error) {
+	user := &model.User{UserID: userID}
+	for _, team := range r.teams {
+		for _, member := range team.Members {
+			if member.UserID != userID {
+				continue
+			}
+			user.IsActive = member.IsActive
+			user.Teams = append(user.Teams, team.TeamName)
+			if member.IsPrimary {
+				user.TeamName = team.TeamName
+			}
+		}
+	}
+	if len(user.Teams) == 0 {
+		return nil, repository.ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (r *reviewRepo) GetTeam(ctx context.Context, teamName string) (*model.Team, error) {
+	team, ok := r.teams[teamName]
+	if !ok {
+		return nil, repository.ErrTeamNotFound
+	}
+	return team, nil
+}
+
+func (r *reviewRepo) GetSubTeams(ctx context.Context, parentTeam string) ([]string, error) {
+	var names []string
+	for name, team := range r.teams {
+		if team.ParentTeam == parentTeam {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+func (r *reviewRepo) GetPullRequest(ctx context.Context, prID string) (*model.PullRequest, error) {
+	pr, ok := r.prs[prID]
+	if !ok {
+		return nil, repository.ErrPRNotFound
+	}
+	copied := *pr
+	copied.AssignedReviewers = append([]string{}, pr.AssignedReviewers...)
+	return &copied, nil
+}
+
+func (r *reviewRepo) IsUserAssignedToPR(ctx context.Context, prID string, userID string) (bool, error) {
+	return contains(r.prs[prID].AssignedReviewers, userID), nil
+}
+
+func (r *reviewRepo) ReassignReviewer(ctx context.Context, prID string, oldUserID string, newUserID string) error {
+	pr := r.prs[prID]
+	for i, reviewer := range pr.AssignedReviewers {
+		if reviewer == oldUserID {
+			pr.AssignedReviewers[i] = newUserID
+		}
+	}
+	return nil
+}
+
+func (r *reviewRepo) GetPullRequestLink(ctx context.Context, prID string) (*model.PullRequestLink, error) {
+	return nil, repository.ErrPRLinkNotFound
+}
+
+func member(userID, role string, primary bool) model.TeamMember {
+	return model.TeamMember{UserID: userID, IsActive: true, IsPrimary: primary, Role: role}
+}
+
+// reassignCase — PR pr-1 автора author с ревьюерами reviewers; из oldUserID заменяется на одного из want
+// или, если want пуст, переназначение отказывает с NO_CANDIDATE
+type reassignCase struct {
+	name      string
+	teams     []*model.Team
+	reviewers []string
+	shadows   []string
+	oldUserID string
+	want      []string
+}
+
+func TestReassignReviewer(t *testing.T) {
+	cases := []reassignCase{
+		{
+			// reviewer-ops пришёл в PR эскалацией из ops; замена должна прийти из backend — команды автора
+			name: "picks from the author's team",
+			teams: []*model.Team{
+				{TeamName: "backend", Members: []model.TeamMember{
+					member("author", model.RoleMember, true), member("r1", model.RoleMember, true), member("b2", model.RoleMember, true),
+				}},
+				{TeamName: "ops", Members: []model.TeamMember{
+					member("reviewer-ops", model.RoleMember, true), member("o2", model.RoleMember, true),
+				}},
+			},
+			reviewers: []string{"r1", "reviewer-ops"}, oldUserID: "reviewer-ops",
+			want: []string{"b2"},
+		},
+		{
+			name: "never picks the author",
+			teams: []*model.Team{{TeamName: "backend", Members: []model.TeamMember{
+				member("author", model.RoleMember, true), member("r1", model.RoleMember, true), member("r2", model.RoleMember, true),
+			}}},
+			reviewers: []string{"r1", "r2"}, oldUserID: "r1",
+		},
+		{
+			name: "required senior replaced by a senior",
+			teams: []*model.Team{{
+				TeamName: "backend", RequiredReviewerRoles: []string{model.RoleSenior},
+				Members: []model.TeamMember{
+					member("author", model.RoleMember, true), member("senior1", model.RoleSenior, true),
+					member("r2", model.RoleMember, true), member("dev3", model.RoleMember, true),
+					member("lead1", model.RoleLead, true), member("senior2", model.RoleSenior, true),
+				},
+			}},
+			reviewers: []string{"senior1", "r2"}, oldUserID: "senior1",
+			want: []string{"senior2"},
+		},
+		{
+			name: "required lead replaced by a lead",
+			teams: []*model.Team{{
+				TeamName: "backend", RequiredReviewerRoles: []string{model.RoleLead},
+				Members: []model.TeamMember{
+					member("author", model.RoleMember, true), member("lead1", model.RoleLead, true),
+					member("r2", model.RoleMember, true), member("senior3", model.RoleSenior, true),
+					member("lead2", model.RoleLead, true),
+				},
+			}},
+			reviewers: []string{"lead1", "r2"}, oldUserID: "lead1",
+			want: []string{"lead2"},
+		},
+		{
+			name: "required senior not downgraded",
+			teams: []*model.Team{{
+				TeamName: "backend", RequiredReviewerRoles: []string{model.RoleSenior},
+				Members: []model.TeamMember{
+					member("author", model.RoleMember, true), member("senior1", model.RoleSenior, true),
+					member("r2", model.RoleMember, true), member("dev3", model.RoleMember, true), member("lead4", model.RoleLead, true),
+				},
+			}},
+			reviewers: []string{"senior1", "r2"}, oldUserID: "senior1",
+		},
+		{
+			name: "required lead not downgraded",
+			teams: []*model.Team{{
+				TeamName: "backend", RequiredReviewerRoles: []string{model.RoleLead},
+				Members: []model.TeamMember{
+					member("author", model.RoleMember, true), member("lead1", model.RoleLead, true),
+					member("r2", model.RoleMember, true), member("senior3", model.RoleSenior, true),
+				},
+			}},
+			reviewers: []string{"lead1", "r2"}, oldUserID: "lead1",
+		},
+		{
+			// роль, которую закрывает другой ревьюер, не обязывает замену
+			name: "role covered by another reviewer",
+			teams: []*model.Team{{
+				TeamName: "backend", RequiredReviewerRoles: []string{model.RoleSenior},
+				Members: []model.TeamMember{
+					member("author", model.RoleMember, true), member("senior1", model.RoleSenior, true),
+					member("r2", model.RoleMember, true), member("dev3", model.RoleMember, true),
+				},
+			}},
+			reviewers: []string{"senior1", "r2"}, oldUserID: "r2",
+			want: []string{"dev3"},
+		},
+		{
+			name: "mentorship never picks trainees",
+			teams: []*model.Team{{
+				TeamName: "backend", Mentorship: true,
+				Members: []model.TeamMember{
+					member("author", model.RoleMember, true), member("senior1", model.RoleSenior, true),
+					member("r2", model.RoleMember, true),
+					member("trainee1", model.RoleTrainee, true), member("trainee2", model.RoleTrainee, true),
+				},
+			}},
+			reviewers: []string{"senior1", "r2"}, shadows: []string{"trainee1"}, oldUserID: "r2",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			runReassignCase(t, tc)
+		})
+	}
+}
+
+// runReassignCase повторяет переназначение, чтобы случайный выбор не скрыл недопустимого кандидата
+func runReassignCase(t *testing.T, tc reassignCase) {
+	t.Helper()
+	repo := newReviewRepo(tc.teams...)
+	s := &service{repo: repo, notifier: discardNotifier{}}
+
+	for i := 0; i < 20; i++ {
+		repo.prs["pr-1"] = &model.PullRequest{
+			PullRequestID: "pr-1", AuthorID: "author", Status: "OPEN",
+			AssignedReviewers: append([]string{}, tc.reviewers...), ShadowReviewers: tc.shadows,
+		}
+		_, newReviewer, err := s.ReassignReviewer(context.Background(), "pr-1", tc.oldUserID)
+		if len(tc.want) == 0 {
+			bErr, ok := err.(BusinessError)
+			if !ok || bErr.Code != "NO_CANDIDATE" {
+				t.Fatalf("expected NO_CANDIDATE, got %q, %v", newReviewer, err)
+			}
+			return
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !contains(tc.want, newReviewer) {
+			t.Fatalf("replacement %q, want one of %v", newReviewer, tc.want)
+		}
+	}
+}
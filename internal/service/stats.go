@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"review-service/internal/model"
+)
+
+func (s *service) GetReviewerStats(ctx context.Context, filter model.StatsFilter) ([]*model.ReviewerStats, error) {
+	if err := validateStatsRange(filter); err != nil {
+		return nil, err
+	}
+	if filter.TeamName != "" {
+		exists, err := s.repo.TeamExists(ctx, filter.TeamName)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, NewBusinessError("NOT_FOUND", "team not found", nil)
+		}
+	}
+
+	return s.repo.GetReviewerStats(ctx, filter)
+}
+
+func (s *service) GetTeamStats(ctx context.Context, filter model.StatsFilter) ([]*model.TeamStats, error) {
+	if err := validateStatsRange(filter); err != nil {
+		return nil, err
+	}
+	if filter.TeamName != "" {
+		exists, err := s.repo.TeamExists(ctx, filter.TeamName)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, NewBusinessError("NOT_FOUND", "team not found", nil)
+		}
+	}
+
+	return s.repo.GetTeamStats(ctx, filter)
+}
+
+// validateStatsRange проверяет, что полуинтервал [from, to) не пуст
+func validateStatsRange(filter model.StatsFilter) error {
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return NewBusinessError("INVALID_INPUT", "from must be before to", nil)
+	}
+	return nil
+}
@@ -0,0 +1,372 @@
+package service
+
+import (
+	"context"
+	"review-service/internal/model"
+	"review-service/internal/tracing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedService оборачивает каждый метод Service в спан OpenTelemetry. Вложенные вызовы
+// внутри service (например, CreatePullRequest из вебхука) отдельных спанов не получают.
+type tracedService struct {
+	next Service
+}
+
+// endSpan завершает спан; бизнес-ошибки — ожидаемый ответ клиенту, а не сбой
+func endSpan(span trace.Span, err error) {
+	_, expected := err.(BusinessError)
+	tracing.End(span, err, expected)
+}
+
+func (t *tracedService) CreateTeam(ctx context.Context, team *model.Team, conflictPolicy string) (*model.TeamCreation, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.CreateTeam")
+	result, err := t.next.CreateTeam(ctx, team, conflictPolicy)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) GetTeam(ctx context.Context, teamName string) (*model.Team, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.GetTeam")
+	result, err := t.next.GetTeam(ctx, teamName)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) ProvisionTeam(ctx context.Context, teamName string, userIDs []string) (*model.Team, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.ProvisionTeam")
+	result, err := t.next.ProvisionTeam(ctx, teamName, userIDs)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) UpdateTeamMembers(ctx context.Context, teamName string, add, remove []string, reviewsPolicy string) (*model.Team, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.UpdateTeamMembers")
+	result, err := t.next.UpdateTeamMembers(ctx, teamName, add, remove, reviewsPolicy)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) DisbandTeam(ctx context.Context, teamName string) error {
+	ctx, span := tracing.Tracer().Start(ctx, "service.DisbandTeam")
+	err := t.next.DisbandTeam(ctx, teamName)
+	endSpan(span, err)
+	return err
+}
+
+func (t *tracedService) ListTeams(ctx context.Context, filter model.TeamListFilter) (*model.TeamList, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.ListTeams")
+	result, err := t.next.ListTeams(ctx, filter)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) AddTeamMember(ctx context.Context, teamName string, member *model.TeamMember) (*model.Team, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.AddTeamMember")
+	result, err := t.next.AddTeamMember(ctx, teamName, member)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) RemoveTeamMember(ctx context.Context, teamName, userID, reviewsPolicy string) (*model.MembershipChange, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.RemoveTeamMember")
+	result, err := t.next.RemoveTeamMember(ctx, teamName, userID, reviewsPolicy)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) SetTeamParent(ctx context.Context, teamName, parentTeam string) (*model.Team, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.SetTeamParent")
+	result, err := t.next.SetTeamParent(ctx, teamName, parentTeam)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) SetTeamEscalation(ctx context.Context, teamName, escalation string) (*model.Team, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.SetTeamEscalation")
+	result, err := t.next.SetTeamEscalation(ctx, teamName, escalation)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) SetTeamReviewerRoles(ctx context.Context, teamName string, roles []string) (*model.Team, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.SetTeamReviewerRoles")
+	result, err := t.next.SetTeamReviewerRoles(ctx, teamName, roles)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) SetTeamMentorship(ctx context.Context, teamName string, enabled bool) (*model.Team, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.SetTeamMentorship")
+	result, err := t.next.SetTeamMentorship(ctx, teamName, enabled)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) SetMemberRole(ctx context.Context, teamName, userID, role string) (*model.Team, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.SetMemberRole")
+	result, err := t.next.SetMemberRole(ctx, teamName, userID, role)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) GetTeamTree(ctx context.Context, rootTeam string) ([]*model.TeamTreeNode, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.GetTeamTree")
+	result, err := t.next.GetTeamTree(ctx, rootTeam)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) RenameTeam(ctx context.Context, teamName, newName string) (*model.Team, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.RenameTeam")
+	result, err := t.next.RenameTeam(ctx, teamName, newName)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) DeleteTeam(ctx context.Context, teamName, destinationTeam string, reparentSubTeams bool) error {
+	ctx, span := tracing.Tracer().Start(ctx, "service.DeleteTeam")
+	err := t.next.DeleteTeam(ctx, teamName, destinationTeam, reparentSubTeams)
+	endSpan(span, err)
+	return err
+}
+
+func (t *tracedService) ImportTeams(ctx context.Context, format string, data []byte, dryRun bool) (*model.ImportResult, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.ImportTeams")
+	result, err := t.next.ImportTeams(ctx, format, data, dryRun)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) ExportTeams(ctx context.Context) ([]model.ImportTeam, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.ExportTeams")
+	result, err := t.next.ExportTeams(ctx)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) GetReviewerStats(ctx context.Context, filter model.StatsFilter) ([]*model.ReviewerStats, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.GetReviewerStats")
+	result, err := t.next.GetReviewerStats(ctx, filter)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) GetTeamStats(ctx context.Context, filter model.StatsFilter) ([]*model.TeamStats, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.GetTeamStats")
+	result, err := t.next.GetTeamStats(ctx, filter)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) GetUser(ctx context.Context, userID string) (*model.User, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.GetUser")
+	result, err := t.next.GetUser(ctx, userID)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) CreateUser(ctx context.Context, user *model.User) (*model.User, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.CreateUser")
+	result, err := t.next.CreateUser(ctx, user)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) ListUsers(ctx context.Context, filter model.UserFilter) (*model.UserList, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.ListUsers")
+	result, err := t.next.ListUsers(ctx, filter)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) UpdateUser(ctx context.Context, update *model.UserUpdate) (*model.User, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.UpdateUser")
+	result, err := t.next.UpdateUser(ctx, update)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) SetUserActive(ctx context.Context, userID string, isActive bool) (*model.User, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.SetUserActive")
+	result, err := t.next.SetUserActive(ctx, userID, isActive)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) SetUserAbsence(ctx context.Context, userID string, from, until *time.Time) (*model.User, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.SetUserAbsence")
+	result, err := t.next.SetUserAbsence(ctx, userID, from, until)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) MoveUserToTeam(ctx context.Context, userID, teamName, reviewsPolicy string) (*model.MembershipChange, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.MoveUserToTeam")
+	result, err := t.next.MoveUserToTeam(ctx, userID, teamName, reviewsPolicy)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) GetUserReviewRequests(ctx context.Context, userID string) ([]*model.PullRequestShort, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.GetUserReviewRequests")
+	result, err := t.next.GetUserReviewRequests(ctx, userID)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) CreatePullRequest(ctx context.Context, prID, prName, authorID string) (*model.PullRequest, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.CreatePullRequest")
+	result, err := t.next.CreatePullRequest(ctx, prID, prName, authorID)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) MergePullRequest(ctx context.Context, prID string) (*model.PullRequest, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.MergePullRequest")
+	result, err := t.next.MergePullRequest(ctx, prID)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) ClosePullRequest(ctx context.Context, prID string) (*model.PullRequest, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.ClosePullRequest")
+	result, err := t.next.ClosePullRequest(ctx, prID)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) ReopenPullRequest(ctx context.Context, prID string) (*model.PullRequest, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.ReopenPullRequest")
+	result, err := t.next.ReopenPullRequest(ctx, prID)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) ReassignReviewer(ctx context.Context, prID string, oldUserID string) (*model.PullRequest, string, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.ReassignReviewer")
+	result, newReviewerID, err := t.next.ReassignReviewer(ctx, prID, oldUserID)
+	endSpan(span, err)
+	return result, newReviewerID, err
+}
+
+func (t *tracedService) SubmitVerdict(ctx context.Context, prID, userID, verdict string) (*model.PullRequest, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.SubmitVerdict")
+	result, err := t.next.SubmitVerdict(ctx, prID, userID, verdict)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) AddShadowReviewer(ctx context.Context, prID, userID string) (*model.PullRequest, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.AddShadowReviewer")
+	result, err := t.next.AddShadowReviewer(ctx, prID, userID)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) RemoveShadowReviewer(ctx context.Context, prID, userID string) (*model.PullRequest, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.RemoveShadowReviewer")
+	result, err := t.next.RemoveShadowReviewer(ctx, prID, userID)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) LinkUserIdentity(ctx context.Context, identity *model.UserIdentity) (*model.UserIdentity, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.LinkUserIdentity")
+	result, err := t.next.LinkUserIdentity(ctx, identity)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) HandlePullRequestEvent(ctx context.Context, event *model.PullRequestEvent) (*model.WebhookResult, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.HandlePullRequestEvent")
+	result, err := t.next.HandlePullRequestEvent(ctx, event)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) SetNotificationPreference(ctx context.Context, update *model.NotificationPreferenceUpdate) (*model.NotificationPreference, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.SetNotificationPreference")
+	result, err := t.next.SetNotificationPreference(ctx, update)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) DeleteNotificationPreference(ctx context.Context, userID, channel string) error {
+	ctx, span := tracing.Tracer().Start(ctx, "service.DeleteNotificationPreference")
+	err := t.next.DeleteNotificationPreference(ctx, userID, channel)
+	endSpan(span, err)
+	return err
+}
+
+func (t *tracedService) GetNotificationPreferences(ctx context.Context, userID string) ([]*model.NotificationPreference, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.GetNotificationPreferences")
+	result, err := t.next.GetNotificationPreferences(ctx, userID)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) GetPullRequest(ctx context.Context, prID string) (*model.PullRequest, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.GetPullRequest")
+	result, err := t.next.GetPullRequest(ctx, prID)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) IssueAPIToken(ctx context.Context, userID, name string, scopes []string, expiresAt *time.Time) (*model.IssuedToken, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.IssueAPIToken")
+	result, err := t.next.IssueAPIToken(ctx, userID, name, scopes, expiresAt)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) AuthenticateAPIToken(ctx context.Context, token string) (*model.APIToken, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.AuthenticateAPIToken")
+	result, err := t.next.AuthenticateAPIToken(ctx, token)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) GetAPIToken(ctx context.Context, tokenID int64) (*model.APIToken, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.GetAPIToken")
+	result, err := t.next.GetAPIToken(ctx, tokenID)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) ListAPITokens(ctx context.Context, userID string) ([]*model.APIToken, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.ListAPITokens")
+	result, err := t.next.ListAPITokens(ctx, userID)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) RevokeAPIToken(ctx context.Context, tokenID int64, ownerID string) error {
+	ctx, span := tracing.Tracer().Start(ctx, "service.RevokeAPIToken")
+	err := t.next.RevokeAPIToken(ctx, tokenID, ownerID)
+	endSpan(span, err)
+	return err
+}
+
+func (t *tracedService) ListAuditLog(ctx context.Context, filter model.AuditFilter) (*model.AuditLog, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.ListAuditLog")
+	result, err := t.next.ListAuditLog(ctx, filter)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) GetPullRequestHistory(ctx context.Context, prID string) (*model.PullRequestHistory, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.GetPullRequestHistory")
+	result, err := t.next.GetPullRequestHistory(ctx, prID)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedService) GetPullRequestAsOf(ctx context.Context, prID string, asOf time.Time) (*model.PullRequest, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "service.GetPullRequestAsOf")
+	result, err := t.next.GetPullRequestAsOf(ctx, prID, asOf)
+	endSpan(span, err)
+	return result, err
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"review-service/internal/model"
+	"review-service/internal/repository"
+)
+
+// SubmitVerdict записывает вердикт ревьюера userID. Вердикт может оставить только назначенный на PR
+// ревьюер (основной или теневой); вердикты теневых видны в PR, но в кворум не входят.
+func (s *service) SubmitVerdict(ctx context.Context, prID, userID, verdict string) (*model.PullRequest, error) {
+	if prID == "" || userID == "" {
+		return nil, ErrInvalidInput
+	}
+	if verdict != model.VerdictApproved && verdict != model.VerdictChangesRequested {
+		return nil, NewBusinessError("INVALID_INPUT", "verdict must be approved or changes_requested", nil)
+	}
+
+	if err := s.repo.SubmitVerdict(ctx, prID, userID, verdict); err != nil {
+		switch err {
+		case repository.ErrPRNotFound:
+			return nil, NewBusinessError("NOT_FOUND", "PR not found", err)
+		case repository.ErrPRMerged:
+			return nil, NewBusinessError("PR_MERGED", "cannot submit verdict on merged PR", err)
+		case repository.ErrPRClosed:
+			return nil, NewBusinessError("PR_CLOSED", "cannot submit verdict on closed PR", err)
+		case repository.ErrUserNotAssigned:
+			return nil, NewBusinessError("FORBIDDEN", "only assigned reviewers can submit verdicts", err)
+		}
+		return nil, err
+	}
+
+	return s.repo.GetPullRequest(ctx, prID)
+}
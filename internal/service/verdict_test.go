@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"review-service/internal/model"
+	"review-service/internal/repository"
+	"testing"
+)
+
+func (r *reviewRepo) SubmitVerdict(ctx context.Context, prID, userID, verdict string) error {
+	pr, ok := r.prs[prID]
+	if !ok {
+		return repository.ErrPRNotFound
+	}
+	if !contains(pr.AssignedReviewers, userID) && !contains(pr.ShadowReviewers, userID) {
+		return repository.ErrUserNotAssigned
+	}
+	if pr.Verdicts == nil {
+		pr.Verdicts = map[string]string{}
+	}
+	pr.Verdicts[userID] = verdict
+	pr.QuorumReached = pr.HasQuorum()
+	return nil
+}
+
+func TestSubmitVerdict(t *testing.T) {
+	repo := newReviewRepo()
+	repo.prs["pr-1"] = &model.PullRequest{
+		PullRequestID: "pr-1", AuthorID: "author", Status: "OPEN",
+		AssignedReviewers: []string{"r1"}, ShadowReviewers: []string{"trainee"},
+	}
+	s := &service{repo: repo, notifier: discardNotifier{}}
+	ctx := context.Background()
+
+	pr, err := s.SubmitVerdict(ctx, "pr-1", "trainee", model.VerdictApproved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr.QuorumReached {
+		t.Fatal("shadow approval reached the quorum")
+	}
+
+	pr, err = s.SubmitVerdict(ctx, "pr-1", "r1", model.VerdictApproved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pr.QuorumReached {
+		t.Fatal("quorum not reached after all assigned reviewers approved")
+	}
+
+	for _, tc := range []struct {
+		userID, verdict, code string
+	}{
+		{"author", model.VerdictApproved, "FORBIDDEN"},
+		{"stranger", model.VerdictChangesRequested, "FORBIDDEN"},
+		{"r1", "lgtm", "INVALID_INPUT"},
+	} {
+		_, err := s.SubmitVerdict(ctx, "pr-1", tc.userID, tc.verdict)
+		if bErr, ok := err.(BusinessError); !ok || bErr.Code != tc.code {
+			t.Errorf("%s %s: expected %s, got %v", tc.userID, tc.verdict, tc.code, err)
+		}
+	}
+}
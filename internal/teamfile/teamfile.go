@@ -0,0 +1,368 @@
+// Package teamfile читает и пишет описание команд и их участников в YAML и CSV
+// для массового импорта и экспорта.
+package teamfile
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"review-service/internal/model"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	FormatYAML = "yaml"
+	FormatCSV  = "csv"
+)
+
+// Колонки CSV. Строка с пустым user_id описывает только команду.
+// Роли в required_reviewer_roles разделяются точкой с запятой.
+var csvColumns = []string{
+	"team_name", "parent_team", "escalation", "required_reviewer_roles", "mentorship",
+	"user_id", "username", "is_active", "role", "is_primary",
+}
+
+// FormatFromName определяет формат по расширению файла
+func FormatFromName(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return FormatCSV
+	case ".yaml", ".yml":
+		return FormatYAML
+	}
+	return ""
+}
+
+// Parse разбирает файл импорта. Ошибки возвращаются с номерами строк; при синтаксической
+// ошибке разбор прекращается, при ошибках в отдельных строках продолжается.
+func Parse(format string, data []byte) ([]model.ImportTeam, []model.ImportError) {
+	switch format {
+	case FormatYAML:
+		return parseYAML(data)
+	case FormatCSV:
+		return parseCSV(data)
+	}
+	return nil, []model.ImportError{{Message: "format must be yaml or csv"}}
+}
+
+// Write выгружает команды в том же формате, который принимает Parse
+func Write(w io.Writer, format string, teams []model.ImportTeam) error {
+	switch format {
+	case FormatYAML:
+		return writeYAML(w, teams)
+	case FormatCSV:
+		return writeCSV(w, teams)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+type yamlFile struct {
+	Teams []yamlTeam `yaml:"teams"`
+}
+
+type yamlTeam struct {
+	TeamName              string       `yaml:"team_name"`
+	ParentTeam            string       `yaml:"parent_team,omitempty"`
+	Escalation            string       `yaml:"escalation,omitempty"`
+	RequiredReviewerRoles []string     `yaml:"required_reviewer_roles,omitempty"`
+	Mentorship            *bool        `yaml:"mentorship,omitempty"`
+	Members               []yamlMember `yaml:"members"`
+	line                  int
+}
+
+type yamlMember struct {
+	UserID    string `yaml:"user_id"`
+	Username  string `yaml:"username,omitempty"`
+	IsActive  *bool  `yaml:"is_active,omitempty"`
+	Role      string `yaml:"role,omitempty"`
+	IsPrimary bool   `yaml:"is_primary,omitempty"`
+	line      int
+}
+
+func (t *yamlTeam) UnmarshalYAML(value *yaml.Node) error {
+	type plain yamlTeam
+	if err := value.Decode((*plain)(t)); err != nil {
+		return err
+	}
+	t.line = value.Line
+	return nil
+}
+
+func (m *yamlMember) UnmarshalYAML(value *yaml.Node) error {
+	type plain yamlMember
+	if err := value.Decode((*plain)(m)); err != nil {
+		return err
+	}
+	m.line = value.Line
+	return nil
+}
+
+func parseYAML(data []byte) ([]model.ImportTeam, []model.ImportError) {
+	var file yamlFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, yamlErrors(err)
+	}
+
+	teams := make([]model.ImportTeam, 0, len(file.Teams))
+	for _, t := range file.Teams {
+		team := model.ImportTeam{
+			TeamName:              t.TeamName,
+			ParentTeam:            t.ParentTeam,
+			Escalation:            t.Escalation,
+			RequiredReviewerRoles: t.RequiredReviewerRoles,
+			Mentorship:            t.Mentorship,
+			Line:                  t.line,
+		}
+		for _, m := range t.Members {
+			team.Members = append(team.Members, model.ImportMember{
+				UserID:    m.UserID,
+				Username:  m.Username,
+				IsActive:  m.IsActive,
+				Role:      m.Role,
+				IsPrimary: m.IsPrimary,
+				Line:      m.line,
+			})
+		}
+		teams = append(teams, team)
+	}
+
+	return teams, nil
+}
+
+// yamlErrors переводит ошибки yaml.v3 ("line N: ...") в ошибки импорта с номерами строк
+func yamlErrors(err error) []model.ImportError {
+	messages := []string{err.Error()}
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		messages = typeErr.Errors
+	}
+
+	result := make([]model.ImportError, 0, len(messages))
+	for _, message := range messages {
+		message = strings.TrimPrefix(message, "yaml: ")
+		var line int
+		if _, err := fmt.Sscanf(message, "line %d:", &line); err == nil {
+			message = strings.TrimSpace(message[strings.Index(message, ":")+1:])
+		}
+		result = append(result, model.ImportError{Line: line, Message: message})
+	}
+	return result
+}
+
+func parseCSV(data []byte) ([]model.ImportTeam, []model.ImportError) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, []model.ImportError{{Line: 1, Message: "missing header: " + err.Error()}}
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		if !contains(csvColumns, name) {
+			return nil, []model.ImportError{{Line: 1, Message: "unknown column " + name}}
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["team_name"]; !ok {
+		return nil, []model.ImportError{{Line: 1, Message: "team_name column is required"}}
+	}
+
+	var teams []model.ImportTeam
+	index := map[string]int{}
+	var errs []model.ImportError
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return nil, append(errs, model.ImportError{Line: parseErr.Line, Message: parseErr.Err.Error()})
+			}
+			return nil, append(errs, model.ImportError{Message: err.Error()})
+		}
+		line, _ := reader.FieldPos(0)
+
+		get := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		fail := func(message string) {
+			errs = append(errs, model.ImportError{Line: line, Message: message})
+		}
+
+		teamName := get("team_name")
+		if teamName == "" {
+			fail("team_name is required")
+			continue
+		}
+		i, ok := index[teamName]
+		if !ok {
+			i = len(teams)
+			index[teamName] = i
+			teams = append(teams, model.ImportTeam{TeamName: teamName, Line: line})
+		}
+		team := &teams[i]
+
+		if !mergeValue(&team.ParentTeam, get("parent_team")) {
+			fail("conflicting parent_team for team " + teamName)
+		}
+		if !mergeValue(&team.Escalation, get("escalation")) {
+			fail("conflicting escalation for team " + teamName)
+		}
+		if value := get("required_reviewer_roles"); value != "" {
+			roles := strings.Split(value, ";")
+			for j := range roles {
+				roles[j] = strings.TrimSpace(roles[j])
+			}
+			if team.RequiredReviewerRoles != nil && strings.Join(team.RequiredReviewerRoles, ";") != strings.Join(roles, ";") {
+				fail("conflicting required_reviewer_roles for team " + teamName)
+			}
+			team.RequiredReviewerRoles = roles
+		}
+		if mentorship, err := parseBool(get("mentorship")); err != nil {
+			fail("mentorship must be true or false")
+		} else if mentorship != nil {
+			if team.Mentorship != nil && *team.Mentorship != *mentorship {
+				fail("conflicting mentorship for team " + teamName)
+			}
+			team.Mentorship = mentorship
+		}
+
+		member := model.ImportMember{
+			UserID:   get("user_id"),
+			Username: get("username"),
+			Role:     get("role"),
+			Line:     line,
+		}
+		if member.UserID == "" {
+			if member.Username != "" || member.Role != "" || get("is_active") != "" || get("is_primary") != "" {
+				fail("user_id is required for member columns")
+			}
+			continue
+		}
+		if member.IsActive, err = parseBool(get("is_active")); err != nil {
+			fail("is_active must be true or false")
+			continue
+		}
+		isPrimary, err := parseBool(get("is_primary"))
+		if err != nil {
+			fail("is_primary must be true or false")
+			continue
+		}
+		member.IsPrimary = isPrimary != nil && *isPrimary
+		team.Members = append(team.Members, member)
+	}
+
+	return teams, errs
+}
+
+// mergeValue записывает value в target, если target пуст; false — если значения расходятся
+func mergeValue(target *string, value string) bool {
+	if value == "" || *target == value {
+		return true
+	}
+	if *target != "" {
+		return false
+	}
+	*target = value
+	return true
+}
+
+func parseBool(value string) (*bool, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+func writeYAML(w io.Writer, teams []model.ImportTeam) error {
+	file := yamlFile{Teams: make([]yamlTeam, 0, len(teams))}
+	for _, team := range teams {
+		t := yamlTeam{
+			TeamName:              team.TeamName,
+			ParentTeam:            team.ParentTeam,
+			Escalation:            team.Escalation,
+			RequiredReviewerRoles: team.RequiredReviewerRoles,
+			Mentorship:            team.Mentorship,
+			Members:               make([]yamlMember, 0, len(team.Members)),
+		}
+		for _, member := range team.Members {
+			t.Members = append(t.Members, yamlMember{
+				UserID:    member.UserID,
+				Username:  member.Username,
+				IsActive:  member.IsActive,
+				Role:      member.Role,
+				IsPrimary: member.IsPrimary,
+			})
+		}
+		file.Teams = append(file.Teams, t)
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(file); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+func writeCSV(w io.Writer, teams []model.ImportTeam) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvColumns); err != nil {
+		return err
+	}
+
+	for _, team := range teams {
+		mentorship := ""
+		if team.Mentorship != nil {
+			mentorship = strconv.FormatBool(*team.Mentorship)
+		}
+		teamFields := []string{team.TeamName, team.ParentTeam, team.Escalation, strings.Join(team.RequiredReviewerRoles, ";"), mentorship}
+
+		if len(team.Members) == 0 {
+			if err := writer.Write(append(teamFields, "", "", "", "", "")); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, member := range team.Members {
+			isActive := ""
+			if member.IsActive != nil {
+				isActive = strconv.FormatBool(*member.IsActive)
+			}
+			record := append(append([]string{}, teamFields...),
+				member.UserID, member.Username, isActive, member.Role, strconv.FormatBool(member.IsPrimary))
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func contains(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+-- +goose Up
+ALTER TABLE pull_requests DROP CONSTRAINT pull_requests_status_check;
+ALTER TABLE pull_requests ADD CONSTRAINT pull_requests_status_check CHECK (status IN ('OPEN', 'MERGED', 'CLOSED'));
+ALTER TABLE pull_requests ADD COLUMN closed_at TIMESTAMP;
+
+CREATE TABLE user_identities (
+    provider TEXT NOT NULL,
+    external_login TEXT NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (provider, external_login)
+);
+
+CREATE TABLE webhook_deliveries (
+    provider TEXT NOT NULL,
+    delivery_id TEXT NOT NULL,
+    received_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (provider, delivery_id)
+);
+
+CREATE INDEX idx_user_identities_user ON user_identities(user_id);
+
+-- +goose Down
+DROP TABLE webhook_deliveries;
+DROP TABLE user_identities;
+ALTER TABLE pull_requests DROP COLUMN closed_at;
+UPDATE pull_requests SET status = 'OPEN' WHERE status = 'CLOSED';
+ALTER TABLE pull_requests DROP CONSTRAINT pull_requests_status_check;
+ALTER TABLE pull_requests ADD CONSTRAINT pull_requests_status_check CHECK (status IN ('OPEN', 'MERGED'));
@@ -0,0 +1,26 @@
+-- +goose Up
+CREATE TABLE pull_request_links (
+    pull_request_id TEXT PRIMARY KEY REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    provider TEXT NOT NULL,
+    repository TEXT NOT NULL,
+    number INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE reviewer_sync_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    action TEXT NOT NULL CHECK (action IN ('REQUEST', 'REMOVE')),
+    user_ids TEXT[] NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_reviewer_sync_jobs_pending ON reviewer_sync_jobs(next_attempt_at) WHERE completed_at IS NULL;
+
+-- +goose Down
+DROP TABLE reviewer_sync_jobs;
+DROP TABLE pull_request_links;
@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE notification_preferences (
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    channel TEXT NOT NULL,
+    target TEXT NOT NULL,
+    on_assigned BOOLEAN NOT NULL DEFAULT TRUE,
+    on_reassigned BOOLEAN NOT NULL DEFAULT TRUE,
+    on_merged BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (user_id, channel)
+);
+
+-- +goose Down
+DROP TABLE notification_preferences;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE notification_preferences ADD COLUMN daily_digest BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE notification_preferences DROP COLUMN daily_digest;
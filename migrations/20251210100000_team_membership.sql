@@ -0,0 +1,16 @@
+-- +goose Up
+-- Пользователь, удалённый из команды, остаётся в системе (его PR и ревью ссылаются на users), но без команды
+ALTER TABLE users ALTER COLUMN team_name DROP NOT NULL;
+
+-- +goose Down
+-- Пользователей без команды некуда вернуть, а удалять их нельзя — откат возможен, только пока таких нет
+-- +goose StatementBegin
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM users WHERE team_name IS NULL) THEN
+        RAISE EXCEPTION 'cannot roll back: users without a team exist; assign them to a team first';
+    END IF;
+END;
+$$;
+-- +goose StatementEnd
+ALTER TABLE users ALTER COLUMN team_name SET NOT NULL;
@@ -0,0 +1,26 @@
+-- +goose Up
+CREATE TABLE team_memberships (
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    team_name TEXT NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE,
+    is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+    joined_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (user_id, team_name)
+);
+
+-- У пользователя не больше одной основной команды
+CREATE UNIQUE INDEX idx_team_memberships_primary ON team_memberships(user_id) WHERE is_primary;
+CREATE INDEX idx_team_memberships_team ON team_memberships(team_name);
+
+INSERT INTO team_memberships (user_id, team_name, is_primary, joined_at)
+SELECT user_id, team_name, TRUE, created_at FROM users WHERE team_name IS NOT NULL;
+
+DROP INDEX idx_users_team_active;
+ALTER TABLE users DROP COLUMN team_name;
+
+-- +goose Down
+ALTER TABLE users ADD COLUMN team_name TEXT REFERENCES teams(team_name) ON DELETE CASCADE;
+UPDATE users u SET team_name = tm.team_name
+FROM team_memberships tm
+WHERE tm.user_id = u.user_id AND tm.is_primary;
+CREATE INDEX idx_users_team_active ON users(team_name, is_active);
+DROP TABLE team_memberships;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Команду, у которой есть подкоманды, нельзя удалить, пока их не перевесят (ON DELETE RESTRICT)
+ALTER TABLE teams ADD COLUMN parent_team TEXT REFERENCES teams(team_name) ON DELETE RESTRICT;
+ALTER TABLE teams ADD COLUMN escalation TEXT NOT NULL DEFAULT 'none'
+    CHECK (escalation IN ('none', 'siblings', 'parent', 'siblings_then_parent'));
+ALTER TABLE teams ADD CONSTRAINT teams_parent_not_self CHECK (parent_team <> team_name);
+
+CREATE INDEX idx_teams_parent ON teams(parent_team);
+
+-- +goose Down
+DROP INDEX idx_teams_parent;
+ALTER TABLE teams DROP CONSTRAINT teams_parent_not_self;
+ALTER TABLE teams DROP COLUMN escalation;
+ALTER TABLE teams DROP COLUMN parent_team;
@@ -0,0 +1,60 @@
+-- +goose Up
+-- Команды получают суррогатный ключ: переименование меняет одну строку в teams,
+-- а удаление команды с участниками или подкомандами должно быть явным (ON DELETE RESTRICT)
+ALTER TABLE teams ADD COLUMN team_id BIGSERIAL;
+ALTER TABLE teams ADD COLUMN parent_team_id BIGINT;
+UPDATE teams t SET parent_team_id = p.team_id FROM teams p WHERE t.parent_team = p.team_name;
+
+ALTER TABLE team_memberships ADD COLUMN team_id BIGINT;
+UPDATE team_memberships tm SET team_id = t.team_id FROM teams t WHERE tm.team_name = t.team_name;
+
+ALTER TABLE team_memberships DROP CONSTRAINT team_memberships_pkey;
+ALTER TABLE team_memberships DROP COLUMN team_name;
+ALTER TABLE team_memberships ALTER COLUMN team_id SET NOT NULL;
+ALTER TABLE team_memberships ADD PRIMARY KEY (user_id, team_id);
+
+ALTER TABLE teams DROP CONSTRAINT teams_parent_not_self;
+ALTER TABLE teams DROP COLUMN parent_team;
+ALTER TABLE teams DROP CONSTRAINT teams_pkey;
+ALTER TABLE teams ADD PRIMARY KEY (team_id);
+ALTER TABLE teams ADD CONSTRAINT teams_team_name_key UNIQUE (team_name);
+ALTER TABLE teams ADD CONSTRAINT teams_parent_team_id_fkey
+    FOREIGN KEY (parent_team_id) REFERENCES teams(team_id) ON DELETE RESTRICT;
+ALTER TABLE teams ADD CONSTRAINT teams_parent_not_self CHECK (parent_team_id <> team_id);
+
+ALTER TABLE team_memberships ADD CONSTRAINT team_memberships_team_id_fkey
+    FOREIGN KEY (team_id) REFERENCES teams(team_id) ON DELETE RESTRICT;
+
+CREATE INDEX idx_team_memberships_team ON team_memberships(team_id);
+CREATE INDEX idx_teams_parent ON teams(parent_team_id);
+
+-- +goose Down
+ALTER TABLE team_memberships DROP CONSTRAINT team_memberships_team_id_fkey;
+ALTER TABLE teams DROP CONSTRAINT teams_parent_not_self;
+ALTER TABLE teams DROP CONSTRAINT teams_parent_team_id_fkey;
+DROP INDEX idx_teams_parent;
+DROP INDEX idx_team_memberships_team;
+
+ALTER TABLE teams ADD COLUMN parent_team TEXT;
+UPDATE teams t SET parent_team = p.team_name FROM teams p WHERE t.parent_team_id = p.team_id;
+ALTER TABLE team_memberships ADD COLUMN team_name TEXT;
+UPDATE team_memberships tm SET team_name = t.team_name FROM teams t WHERE tm.team_id = t.team_id;
+
+ALTER TABLE teams DROP CONSTRAINT teams_team_name_key;
+ALTER TABLE teams DROP CONSTRAINT teams_pkey;
+ALTER TABLE teams ADD PRIMARY KEY (team_name);
+ALTER TABLE teams ADD CONSTRAINT teams_parent_team_fkey
+    FOREIGN KEY (parent_team) REFERENCES teams(team_name) ON DELETE RESTRICT;
+ALTER TABLE teams ADD CONSTRAINT teams_parent_not_self CHECK (parent_team <> team_name);
+CREATE INDEX idx_teams_parent ON teams(parent_team);
+
+ALTER TABLE team_memberships DROP CONSTRAINT team_memberships_pkey;
+ALTER TABLE team_memberships DROP COLUMN team_id;
+ALTER TABLE team_memberships ALTER COLUMN team_name SET NOT NULL;
+ALTER TABLE team_memberships ADD PRIMARY KEY (user_id, team_name);
+ALTER TABLE team_memberships ADD CONSTRAINT team_memberships_team_name_fkey
+    FOREIGN KEY (team_name) REFERENCES teams(team_name) ON DELETE CASCADE;
+CREATE INDEX idx_team_memberships_team ON team_memberships(team_name);
+
+ALTER TABLE teams DROP COLUMN parent_team_id;
+ALTER TABLE teams DROP COLUMN team_id;
@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE team_memberships ADD COLUMN role TEXT NOT NULL DEFAULT 'member'
+    CHECK (role IN ('lead', 'senior', 'member', 'trainee'));
+
+-- Роли, представитель каждой из которых назначается ревьюером на каждый PR команды
+ALTER TABLE teams ADD COLUMN required_reviewer_roles TEXT[] NOT NULL DEFAULT '{}'
+    CHECK (required_reviewer_roles <@ ARRAY['lead', 'senior', 'member', 'trainee']);
+
+-- +goose Down
+ALTER TABLE teams DROP COLUMN required_reviewer_roles;
+ALTER TABLE team_memberships DROP COLUMN role;
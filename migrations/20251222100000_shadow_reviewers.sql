@@ -0,0 +1,11 @@
+-- +goose Up
+-- Теневые ревьюеры (стажёры) наблюдают за ревью и не входят в число назначенных ревьюеров PR
+ALTER TABLE pr_reviewers ADD COLUMN is_shadow BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- При включённом наставничестве на PR команды назначается стажёр в паре с senior
+ALTER TABLE teams ADD COLUMN mentorship BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE teams DROP COLUMN mentorship;
+DELETE FROM pr_reviewers WHERE is_shadow;
+ALTER TABLE pr_reviewers DROP COLUMN is_shadow;
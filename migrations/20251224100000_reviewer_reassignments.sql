@@ -0,0 +1,17 @@
+-- +goose Up
+-- Журнал переназначений ревьюеров для статистики. new_user_id пуст, если ревьюера сняли без замены.
+CREATE TABLE reviewer_reassignments (
+    reassignment_id BIGSERIAL PRIMARY KEY,
+    pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    old_user_id TEXT NOT NULL REFERENCES users(user_id),
+    new_user_id TEXT REFERENCES users(user_id),
+    reason TEXT NOT NULL CHECK (reason IN ('manual', 'team_change')),
+    reassigned_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_reviewer_reassignments_at ON reviewer_reassignments(reassigned_at);
+CREATE INDEX idx_pr_reviewers_assigned_at ON pr_reviewers(assigned_at);
+
+-- +goose Down
+DROP INDEX idx_pr_reviewers_assigned_at;
+DROP TABLE reviewer_reassignments;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- API-токены. Хранится только SHA-256 от токена; сам токен показывается один раз при выпуске.
+CREATE TABLE api_tokens (
+    token_id BIGSERIAL PRIMARY KEY,
+    token_hash TEXT NOT NULL UNIQUE,
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    scopes TEXT[] NOT NULL CHECK (scopes <@ ARRAY['read', 'write', 'admin']::TEXT[] AND cardinality(scopes) > 0),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX idx_api_tokens_user ON api_tokens(user_id);
+
+-- +goose Down
+DROP TABLE api_tokens;
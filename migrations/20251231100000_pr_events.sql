@@ -0,0 +1,54 @@
+-- +goose Up
+-- История PR: создание, назначения и снятия ревьюеров, переназначения, смена статуса.
+-- user_id — затронутый ревьюер (при переназначении — новый, old_user_id — прежний), у created — автор.
+CREATE TABLE pr_events (
+    event_id BIGSERIAL PRIMARY KEY,
+    pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    event_type TEXT NOT NULL CHECK (event_type IN ('created', 'assigned', 'unassigned', 'reassigned', 'merged', 'closed', 'reopened')),
+    user_id TEXT REFERENCES users(user_id),
+    old_user_id TEXT REFERENCES users(user_id),
+    is_shadow BOOLEAN NOT NULL DEFAULT FALSE,
+    reason TEXT,
+    actor_type TEXT NOT NULL,
+    actor_id TEXT NOT NULL,
+    occurred_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_pr_events_pull_request ON pr_events(pull_request_id, event_id);
+
+-- Восстановление истории по имеющимся данным. Исходные ревьюеры — те, кто не пришёл переназначением:
+-- снятые переназначением считаются назначенными при создании PR, оставшиеся — по assigned_at.
+-- Переоткрытия не восстанавливаются: о них данных нет.
+INSERT INTO pr_events (pull_request_id, event_type, user_id, is_shadow, actor_type, actor_id, occurred_at)
+SELECT pull_request_id, 'created', author_id, FALSE, 'system', 'migration', created_at FROM pull_requests;
+
+INSERT INTO pr_events (pull_request_id, event_type, user_id, is_shadow, actor_type, actor_id, occurred_at)
+SELECT pr.pull_request_id, 'assigned', ra.old_user_id, FALSE, 'system', 'migration', pr.created_at
+FROM (SELECT DISTINCT pull_request_id, old_user_id FROM reviewer_reassignments) ra
+JOIN pull_requests pr ON pr.pull_request_id = ra.pull_request_id
+WHERE NOT EXISTS (
+    SELECT 1 FROM reviewer_reassignments r 
+    WHERE r.pull_request_id = ra.pull_request_id AND r.new_user_id = ra.old_user_id
+)
+UNION ALL
+SELECT prr.pull_request_id, 'assigned', prr.user_id, prr.is_shadow, 'system', 'migration', prr.assigned_at
+FROM pr_reviewers prr
+WHERE NOT EXISTS (
+    SELECT 1 FROM reviewer_reassignments r 
+    WHERE r.pull_request_id = prr.pull_request_id AND r.new_user_id = prr.user_id
+);
+
+INSERT INTO pr_events (pull_request_id, event_type, user_id, old_user_id, reason, actor_type, actor_id, occurred_at)
+SELECT pull_request_id, CASE WHEN new_user_id IS NULL THEN 'unassigned' ELSE 'reassigned' END,
+    COALESCE(new_user_id, old_user_id), CASE WHEN new_user_id IS NULL THEN NULL ELSE old_user_id END,
+    reason, 'system', 'migration', reassigned_at
+FROM reviewer_reassignments
+ORDER BY reassignment_id;
+
+INSERT INTO pr_events (pull_request_id, event_type, actor_type, actor_id, occurred_at)
+SELECT pull_request_id, 'merged', 'system', 'migration', merged_at FROM pull_requests WHERE merged_at IS NOT NULL
+UNION ALL
+SELECT pull_request_id, 'closed', 'system', 'migration', closed_at FROM pull_requests WHERE status = 'CLOSED' AND closed_at IS NOT NULL;
+
+-- +goose Down
+DROP TABLE pr_events;
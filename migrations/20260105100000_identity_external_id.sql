@@ -0,0 +1,9 @@
+-- +goose Up
+-- Числовой id аккаунта на code host'е: в отличие от логина он не меняется и есть в событиях,
+-- где логин автора не передаётся (GitLab передаёт только author_id).
+ALTER TABLE user_identities ADD COLUMN external_id TEXT;
+CREATE UNIQUE INDEX idx_user_identities_external_id ON user_identities(provider, external_id) WHERE external_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX idx_user_identities_external_id;
+ALTER TABLE user_identities DROP COLUMN external_id;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Вердикт ревьюера по PR. Хранится в строке назначения: при переназначении новый ревьюер начинает без вердикта.
+ALTER TABLE pr_reviewers ADD COLUMN verdict TEXT CHECK (verdict IN ('approved', 'changes_requested'));
+ALTER TABLE pr_reviewers ADD COLUMN verdict_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE pr_reviewers DROP COLUMN verdict_at;
+ALTER TABLE pr_reviewers DROP COLUMN verdict;
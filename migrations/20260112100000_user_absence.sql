@@ -0,0 +1,10 @@
+-- +goose Up
+-- Отсутствие (отпуск, больничный): в этот период пользователь не выбирается ревьюером
+ALTER TABLE users ADD COLUMN absent_from TIMESTAMP;
+ALTER TABLE users ADD COLUMN absent_until TIMESTAMP;
+ALTER TABLE users ADD CONSTRAINT users_absence_period CHECK (absent_until IS NULL OR absent_from < absent_until);
+
+-- +goose Down
+ALTER TABLE users DROP CONSTRAINT users_absence_period;
+ALTER TABLE users DROP COLUMN absent_until;
+ALTER TABLE users DROP COLUMN absent_from;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- Вердикты ревьюеров в истории PR: user_id — ревьюер, verdict — approved или changes_requested.
+ALTER TABLE pr_events DROP CONSTRAINT pr_events_event_type_check;
+ALTER TABLE pr_events ADD CONSTRAINT pr_events_event_type_check
+    CHECK (event_type IN ('created', 'assigned', 'unassigned', 'reassigned', 'merged', 'closed', 'reopened', 'verdict'));
+ALTER TABLE pr_events ADD COLUMN verdict TEXT CHECK (verdict IN ('approved', 'changes_requested'));
+
+-- Уже поставленные вердикты; заменённые ранее вердикты не восстанавливаются: о них данных нет.
+INSERT INTO pr_events (pull_request_id, event_type, user_id, is_shadow, verdict, actor_type, actor_id, occurred_at)
+SELECT pull_request_id, 'verdict', user_id, is_shadow, verdict, 'system', 'migration', verdict_at
+FROM pr_reviewers
+WHERE verdict IS NOT NULL AND verdict_at IS NOT NULL;
+
+-- +goose Down
+DELETE FROM pr_events WHERE event_type = 'verdict';
+ALTER TABLE pr_events DROP COLUMN verdict;
+ALTER TABLE pr_events DROP CONSTRAINT pr_events_event_type_check;
+ALTER TABLE pr_events ADD CONSTRAINT pr_events_event_type_check
+    CHECK (event_type IN ('created', 'assigned', 'unassigned', 'reassigned', 'merged', 'closed', 'reopened'));
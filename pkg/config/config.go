@@ -9,8 +9,13 @@ import (
 )
 
 type Config struct {
-	Port int
-	DB   DatabaseConfig
+	Port         int
+	LogLevel     string
+	DB           DatabaseConfig
+	Integrations IntegrationsConfig
+	SMTP         SMTPConfig
+	Tracing      TracingConfig
+	OIDC         OIDCConfig
 }
 
 type DatabaseConfig struct {
@@ -21,6 +26,46 @@ type DatabaseConfig struct {
 	DBName   string
 }
 
+// SMTPConfig настройки почтовых уведомлений. TLSMode: "starttls", "tls" или "none".
+type SMTPConfig struct {
+	Host       string
+	Port       int
+	Username   string
+	Password   string
+	From       string
+	TLSMode    string
+	DigestHour int
+}
+
+// TracingConfig настройки OpenTelemetry. Exporter: "none", "stdout" или "otlp";
+// OTLPEndpoint — полный URL приёмника OTLP/HTTP (по умолчанию http://localhost:4318/v1/traces).
+type TracingConfig struct {
+	Exporter     string
+	OTLPEndpoint string
+	ServiceName  string
+}
+
+// OIDCConfig проверка JWT от SSO. Ключи берутся из JWKSFile или JWKSURL; без обоих JWT не принимаются.
+// Если ключи заданы, Issuer и Audience обязательны — без них сервис не запустится.
+// Область admin выдаётся только участникам группы AdminGroup из claim GroupsClaim; пустой AdminGroup — никому.
+type OIDCConfig struct {
+	JWKSURL     string
+	JWKSFile    string
+	Issuer      string
+	Audience    string
+	GroupsClaim string
+	AdminGroup  string
+}
+
+type IntegrationsConfig struct {
+	GitHubWebhookSecret string
+	GitLabWebhookToken  string
+	GitHubAPIURL        string
+	GitHubAPIToken      string
+	// SCIMToken — bearer-токен для /scim/v2; пока не задан, SCIM-провижининг отключён
+	SCIMToken string
+}
+
 func Load() (*Config, error) {
 	_ = godotenv.Load()
 
@@ -47,7 +92,8 @@ func Load() (*Config, error) {
 	}
 
 	return &Config{
-		Port: port,
+		Port:     port,
+		LogLevel: getEnv("LOG_LEVEL", "info"),
 		DB: DatabaseConfig{
 			Host:     getEnv("POSTGRES_HOST", "localhost"),
 			Port:     dbPort,
@@ -55,6 +101,35 @@ func Load() (*Config, error) {
 			Password: getEnv("POSTGRES_PASSWORD", "review_password"),
 			DBName:   getEnv("POSTGRES_DB", "review_service"),
 		},
+		Integrations: IntegrationsConfig{
+			GitHubWebhookSecret: os.Getenv("GITHUB_WEBHOOK_SECRET"),
+			GitLabWebhookToken:  os.Getenv("GITLAB_WEBHOOK_TOKEN"),
+			GitHubAPIURL:        getEnv("GITHUB_API_URL", "https://api.github.com"),
+			GitHubAPIToken:      os.Getenv("GITHUB_API_TOKEN"),
+			SCIMToken:           os.Getenv("SCIM_TOKEN"),
+		},
+		SMTP: SMTPConfig{
+			Host:       os.Getenv("SMTP_HOST"),
+			Port:       getEnvInt("SMTP_PORT", 587),
+			Username:   os.Getenv("SMTP_USERNAME"),
+			Password:   os.Getenv("SMTP_PASSWORD"),
+			From:       getEnv("SMTP_FROM", "review-service@localhost"),
+			TLSMode:    getEnv("SMTP_TLS", "starttls"),
+			DigestHour: getEnvInt("DIGEST_HOUR", 9),
+		},
+		OIDC: OIDCConfig{
+			JWKSURL:     os.Getenv("OIDC_JWKS_URL"),
+			JWKSFile:    os.Getenv("OIDC_JWKS_FILE"),
+			Issuer:      os.Getenv("OIDC_ISSUER"),
+			Audience:    os.Getenv("OIDC_AUDIENCE"),
+			GroupsClaim: getEnv("OIDC_GROUPS_CLAIM", "groups"),
+			AdminGroup:  os.Getenv("OIDC_ADMIN_GROUP"),
+		},
+		Tracing: TracingConfig{
+			Exporter:     getEnv("OTEL_TRACES_EXPORTER", "none"),
+			OTLPEndpoint: os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"),
+			ServiceName:  getEnv("OTEL_SERVICE_NAME", "review-service"),
+		},
 	}, nil
 }
 
@@ -63,4 +138,13 @@ func getEnv(key, defaultValue string) string {
 		return value
 	}
 	return defaultValue
-}
\ No newline at end of file
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if v, err := strconv.Atoi(value); err == nil {
+			return v
+		}
+	}
+	return defaultValue
+}
@@ -3,7 +3,7 @@ package server
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -12,9 +12,6 @@ import (
 )
 
 func Start(port int, handler http.Handler) {
-	// Логи в stdout
-	log.SetOutput(os.Stdout)
-
 	srv := &http.Server{
 		Addr:    fmt.Sprintf(":%d", port),
 		Handler: handler,
@@ -24,15 +21,16 @@ func Start(port int, handler http.Handler) {
 	defer stop()
 
 	go func() {
-		log.Printf("Server starting on port %d", port)
+		slog.Info("server starting", "port", port)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Server failed: %v", err)
+			slog.Error("server failed", "error", err)
+			os.Exit(1)
 		}
 	}()
 
 	<-ctx.Done()
 
-	log.Println("Shutting down review-service")
+	slog.Info("shutting down review-service")
 	
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()